@@ -0,0 +1,120 @@
+package interplanetary
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// daemonAddr is the location of a daemon's API.
+type daemonAddr struct {
+	network string // network to dial: tcp, tcp4, tcp6 or unix
+	address string // address to dial on network
+	host    string // host requests are addressed to
+	scheme  string // http or https
+	prefix  string // path prepended to the API path
+}
+
+// parseAddr parses the address of a daemon. It accepts a multiaddr such as
+// "/ip4/127.0.0.1/tcp/5001", a "host:port" pair, an "http://host:port/prefix"
+// or "https://..." URL, and a unix socket as "unix:///path/to/socket".
+func parseAddr(addr string) (*daemonAddr, error) {
+	switch {
+	case strings.HasPrefix(addr, "/"):
+		return parseMultiaddr(addr)
+	case strings.HasPrefix(addr, "unix:"):
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if path == "" || u.Host != "" {
+			return nil, errors.Errorf("invalid unix socket address: %s", addr)
+		}
+		return &daemonAddr{network: "unix", address: path, host: "localhost", scheme: "http"}, nil
+	case strings.Contains(addr, "://"):
+		return parseURL(addr)
+	default:
+		if err := checkHostPort(addr); err != nil {
+			return nil, err
+		}
+		return &daemonAddr{network: "tcp", address: addr, host: addr, scheme: "http"}, nil
+	}
+}
+
+func parseMultiaddr(addr string) (*daemonAddr, error) {
+	// the multiaddr parser zeroes values it cannot parse, such as a port
+	// that is not a number, so the address is taken apart here instead
+	parts := strings.Split(strings.TrimRight(addr, "/"), "/")
+	if len(parts) != 5 || parts[3] != "tcp" {
+		return nil, errors.Errorf("not a TCP address: %s", addr)
+	}
+	ip := net.ParseIP(parts[2])
+	switch {
+	case ip == nil,
+		parts[1] == "ip4" && ip.To4() == nil,
+		parts[1] != "ip4" && parts[1] != "ip6":
+		return nil, errors.Errorf("invalid multiaddr: %s", addr)
+	}
+	port, err := strconv.ParseUint(parts[4], 10, 16)
+	if err != nil {
+		return nil, errors.Errorf("invalid port: %s", addr)
+	}
+	host := net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10))
+	network := "tcp4"
+	if ip.To4() == nil {
+		network = "tcp6"
+	}
+	return &daemonAddr{network: network, address: host, host: host, scheme: "http"}, nil
+}
+
+func parseURL(addr string) (*daemonAddr, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	var port string
+	switch u.Scheme {
+	case "http":
+		port = "80"
+	case "https":
+		port = "443"
+	default:
+		return nil, errors.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.Errorf("missing host: %s", addr)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	address := net.JoinHostPort(u.Hostname(), port)
+	if err := checkHostPort(address); err != nil {
+		return nil, err
+	}
+	return &daemonAddr{
+		network: "tcp",
+		address: address,
+		host:    u.Host,
+		scheme:  u.Scheme,
+		prefix:  strings.TrimRight(u.Path, "/"),
+	}, nil
+}
+
+// checkHostPort checks that addr is a "host:port" pair with a valid port.
+func checkHostPort(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return errors.Errorf("invalid port: %s", addr)
+	}
+	return nil
+}
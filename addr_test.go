@@ -0,0 +1,102 @@
+package interplanetary
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestParseAddr(t *testing.T) {
+	cases := []struct {
+		addr string
+		want daemonAddr
+	}{
+		{"/ip4/127.0.0.1/tcp/5001", daemonAddr{"tcp4", "127.0.0.1:5001", "127.0.0.1:5001", "http", ""}},
+		{"/ip6/::1/tcp/5001", daemonAddr{"tcp6", "[::1]:5001", "[::1]:5001", "http", ""}},
+		{"/ip6/0:0:0:0:0:0:0:1/tcp/5001", daemonAddr{"tcp6", "[::1]:5001", "[::1]:5001", "http", ""}},
+		{"/ip6/::FFFF:127.0.0.1/tcp/5001", daemonAddr{"tcp4", "127.0.0.1:5001", "127.0.0.1:5001", "http", ""}},
+		{"/ip4/127.0.0.1/tcp/05001", daemonAddr{"tcp4", "127.0.0.1:5001", "127.0.0.1:5001", "http", ""}},
+		{"localhost:5001", daemonAddr{"tcp", "localhost:5001", "localhost:5001", "http", ""}},
+		{"http://example.com:5001", daemonAddr{"tcp", "example.com:5001", "example.com:5001", "http", ""}},
+		{"https://example.com/ipfs/", daemonAddr{"tcp", "example.com:443", "example.com", "https", "/ipfs"}},
+		{"unix:///var/run/ipfs.sock", daemonAddr{"unix", "/var/run/ipfs.sock", "localhost", "http", ""}},
+	}
+	for _, c := range cases {
+		a, err := parseAddr(c.addr)
+		if err != nil {
+			t.Errorf("%s: %s", c.addr, err)
+			continue
+		}
+		if *a != c.want {
+			t.Errorf("%s: got %+v, want %+v", c.addr, *a, c.want)
+		}
+	}
+}
+
+func TestNewClientInvalidAddr(t *testing.T) {
+	for _, addr := range []string{
+		"",
+		"localhost",
+		"localhost:http",
+		"localhost:99999",
+		"/ip4/127.0.0.1",
+		"/ip4/127.0.0.1/udp/5001",
+		"/ip4/127.0.0.1/tcp/notaport",
+		"/ip4/127.0.0.1/tcp/99999",
+		"/ip4/::1/tcp/5001",
+		"/ip4/localhost/tcp/5001",
+		"/ip6/127.0.0.1.1/tcp/5001",
+		"/ip4/127.0.0.1/tcp/5001/http",
+		"ftp://example.com:5001",
+		"http://",
+		"http://example.com:99999",
+		"unix://",
+		"unix://host/path",
+	} {
+		c, err := NewClient(addr)
+		if err == nil {
+			t.Errorf("%q: expected an error", addr)
+		}
+		if c != nil {
+			t.Errorf("%q: expected a nil client", addr)
+		}
+	}
+}
+
+func TestNewClientUnix(t *testing.T) {
+	dir, err := os.MkdirTemp("", "interplanetary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "api.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var path string
+	s := &httptest.Server{
+		Listener: l,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+		})},
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient("unix://" + sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetConfig(context.Background(), "Tour.Last", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/api/v0/config" {
+		t.Errorf("path: got %q", path)
+	}
+}
@@ -0,0 +1,78 @@
+package interplanetary
+
+import (
+	"io"
+	"io/ioutil"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+)
+
+// BlockStat describes a raw block.
+type BlockStat struct {
+	Key    Key
+	Length int
+}
+
+// BlockGet returns the raw data of the block named by k. The caller must
+// close the returned reader.
+func (c *client) BlockGet(ctx context.Context, k Key) (io.ReadCloser, error) {
+	req, err := c.request([]string{"block", "get"}, nil, k.String())
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser(res)
+}
+
+// BlockPut stores the data read from r as a single raw block and returns
+// its key.
+func (c *client) BlockPut(ctx context.Context, r io.Reader) (Key, error) {
+	s, err := c.blockPut(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return s.Key, nil
+}
+
+// BlockStat returns the key and length of the block named by k. The daemon
+// has no stat command for blocks, so the block is fetched and measured.
+func (c *client) BlockStat(ctx context.Context, k Key) (*BlockStat, error) {
+	r, err := c.BlockGet(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	n, err := io.Copy(ioutil.Discard, r)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockStat{Key: k, Length: int(n)}, nil
+}
+
+func (c *client) blockPut(ctx context.Context, r io.Reader) (*BlockStat, error) {
+	req, err := c.request([]string{"block", "put"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetFiles(&cmds.SliceFile{Filename: "", Files: []cmds.File{
+		&cmds.ReaderFile{Filename: "data", Reader: r},
+	}})
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.Block)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	k, err := ParseKey(out.Key)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	return &BlockStat{Key: k, Length: out.Length}, nil
+}
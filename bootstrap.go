@@ -0,0 +1,60 @@
+package interplanetary
+
+import (
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+	peer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/peer"
+	ma "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multiaddr"
+)
+
+// Bootstrap lists the node's bootstrap peers.
+func (c *client) Bootstrap(ctx context.Context) ([]PeerAddr, error) {
+	return c.bootstrap(ctx, "list")
+}
+
+// BootstrapAdd adds peers to the bootstrap list. It returns the peers that
+// were not already in the list.
+func (c *client) BootstrapAdd(ctx context.Context, peers ...PeerAddr) ([]PeerAddr, error) {
+	return c.bootstrap(ctx, "add", peers...)
+}
+
+// BootstrapRemove removes peers from the bootstrap list. It returns the
+// peers that were removed.
+func (c *client) BootstrapRemove(ctx context.Context, peers ...PeerAddr) ([]PeerAddr, error) {
+	return c.bootstrap(ctx, "rm", peers...)
+}
+
+func (c *client) bootstrap(ctx context.Context, sub string, peers ...PeerAddr) ([]PeerAddr, error) {
+	args := make([]string, len(peers))
+	for i, p := range peers {
+		args[i] = p.String()
+	}
+	req, err := c.request([]string{"bootstrap", sub}, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.BootstrapOutput)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+
+	result := make([]PeerAddr, len(out.Peers))
+	for i, bp := range out.Peers {
+		result[i].ID = peer.DecodePrettyID(bp.PeerID)
+		if len(result[i].ID) == 0 {
+			return nil, errMalformed(res.Request(), "malformed response")
+		}
+		if bp.Address == "" {
+			continue
+		}
+		result[i].Addr, err = ma.NewMultiaddr(bp.Address)
+		if err != nil {
+			return nil, wrapMalformed(req, err)
+		}
+	}
+	return result, nil
+}
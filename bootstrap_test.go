@@ -0,0 +1,47 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestBootstrap(t *testing.T) {
+	const (
+		id   = "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ"
+		addr = "/ip4/104.131.131.82/tcp/4001/" + id
+		out  = `{"Peers":[{"Address":"/ip4/104.131.131.82/tcp/4001","PeerID":"` + id + `"}]}`
+	)
+	p, err := ipfs.ParsePeerAddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/v0/bootstrap/list", stubCommand(t, "bootstrap/list", nil, out))
+	mux.Handle("/api/v0/bootstrap/add", stubCommand(t, "bootstrap/add", []string{addr}, out))
+	mux.Handle("/api/v0/bootstrap/rm", stubCommand(t, "bootstrap/rm", []string{addr}, out))
+	c := stubClient(t, mux.ServeHTTP)
+
+	for name, f := range map[string]func(context.Context) ([]ipfs.PeerAddr, error){
+		"list": c.Bootstrap,
+		"add":  func(ctx context.Context) ([]ipfs.PeerAddr, error) { return c.BootstrapAdd(ctx, p) },
+		"rm":   func(ctx context.Context) ([]ipfs.PeerAddr, error) { return c.BootstrapRemove(ctx, p) },
+	} {
+		peers, err := f(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(peers) != 1 || peers[0].String() != addr {
+			t.Errorf("%s: got %v", name, peers)
+		}
+	}
+}
+
+func TestBootstrapMalformed(t *testing.T) {
+	c := stubClient(t, stubCommand(t, "bootstrap/list", nil, `{"Peers":[{"Address":"/ip4/104.131.131.82/tcp/4001","PeerID":"0OIl"}]}`))
+	if _, err := c.Bootstrap(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+}
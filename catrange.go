@@ -0,0 +1,157 @@
+package interplanetary
+
+import (
+	"io"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	dag "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/merkledag"
+	ft "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/unixfs"
+	ftpb "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/unixfs/pb"
+	u "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// CatRange returns length bytes of the file named by k, starting at offset,
+// or the rest of the file if length is negative. Rather than streaming the
+// file from its start, it walks the file's DAG and fetches only the blocks
+// holding the range, using the block sizes recorded in each node.
+//
+// Blocks are fetched in the background as the reader is read. The caller
+// must close the reader, or cancel ctx, once done with it; until then, the
+// fetch of the next block waits on the reader.
+func (c *client) CatRange(ctx context.Context, k Key, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, errNegative
+	}
+	ds := &remoteDAG{ctx, c}
+	root, err := ds.Get(u.Key(k.Multihash()))
+	if err != nil {
+		return nil, err
+	}
+	// check the root before answering, so that a directory or malformed
+	// object fails here rather than on the first Read
+	if _, err := nodeData(root); err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		// unblock a write the reader is not waiting on
+		select {
+		case <-ctx.Done():
+			pw.CloseWithError(errFetchDone(ctx))
+		case <-done:
+		}
+	}()
+	go func() {
+		defer close(done)
+		w := &rangeWriter{ctx: ctx, w: pw, skip: offset, left: length}
+		err := w.walk(ds, root)
+		if err == errRangeDone {
+			err = nil
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+var (
+	// errRangeDone stops a walk once the whole range has been written.
+	errRangeDone  = errors.New("range done")
+	errBlockSizes = errors.New("block sizes do not match links")
+)
+
+// errFetchDone is the error of a range whose blocks are no longer fetched
+// because ctx is done.
+func errFetchDone(ctx context.Context) error {
+	return &Error{Kind: ctx.Err(), Path: []string{"block", "get"}, Err: ctx.Err()}
+}
+
+// errMalformedBlock returns an ErrMalformedResponse error for a block whose
+// content does not decode as the file it is part of.
+func errMalformedBlock(err error) error {
+	return &Error{Kind: ErrMalformedResponse, Path: []string{"block", "get"}, Err: err}
+}
+
+// rangeWriter writes the part of a file's content that falls in a range.
+type rangeWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	skip int64 // bytes still to skip before the range starts
+	left int64 // bytes of the range still to write, or -1 for all
+}
+
+// walk writes the content of the file DAG rooted at n that falls in the
+// range. Children wholly before the range are skipped without being fetched.
+func (r *rangeWriter) walk(ds dag.DAGService, n *dag.Node) error {
+	pbdata, err := nodeData(n)
+	if err != nil {
+		return err
+	}
+	if err := r.write(pbdata.GetData()); err != nil {
+		return err
+	}
+
+	sizes := pbdata.GetBlocksizes()
+	if len(sizes) != len(n.Links) {
+		return errMalformedBlock(errBlockSizes)
+	}
+	for i, l := range n.Links {
+		if size := int64(sizes[i]); r.skip >= size {
+			r.skip -= size
+			continue
+		}
+		select {
+		case <-r.ctx.Done():
+			return errFetchDone(r.ctx)
+		default:
+		}
+		child, err := l.GetNode(ds)
+		if err != nil {
+			return err
+		}
+		if err := r.walk(ds, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rangeWriter) write(data []byte) error {
+	if r.skip >= int64(len(data)) {
+		r.skip -= int64(len(data))
+		return nil
+	}
+	data = data[r.skip:]
+	r.skip = 0
+	if r.left >= 0 && r.left < int64(len(data)) {
+		data = data[:r.left]
+	}
+	if _, err := r.w.Write(data); err != nil {
+		return err
+	}
+	if r.left >= 0 {
+		r.left -= int64(len(data))
+		if r.left == 0 {
+			return errRangeDone
+		}
+	}
+	return nil
+}
+
+// nodeData decodes the unixfs metadata of n, which must be part of a file.
+func nodeData(n *dag.Node) (*ftpb.Data, error) {
+	pbdata, err := ft.FromBytes(n.Data)
+	if err != nil {
+		return nil, errMalformedBlock(err)
+	}
+	switch pbdata.GetType() {
+	case ftpb.Data_File, ftpb.Data_Raw:
+		return pbdata, nil
+	case ftpb.Data_Directory:
+		return nil, errIsDir
+	default:
+		return nil, errMalformedBlock(ft.ErrUnrecognizedType)
+	}
+}
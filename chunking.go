@@ -0,0 +1,65 @@
+package interplanetary
+
+import (
+	"bufio"
+	"io"
+
+	importer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer"
+	chunk "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer/chunk"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// WithChunkSize makes an add split file content into blocks of size bytes,
+// instead of the daemon's default of 256KiB.
+//
+// The daemon cannot be told how to split content, so an add given a chunking
+// option imports its content in the client and pushes the resulting blocks
+// to the daemon one at a time.
+func WithChunkSize(size int) AddOption {
+	return func(o *addOptions) {
+		if size <= 0 || int64(size) > importer.BlockSizeLimit {
+			o.err = errors.Errorf("invalid chunk size %d", size)
+			return
+		}
+		o.splitter = &chunk.SizeSplitter{Size: size}
+	}
+}
+
+// WithRabinChunking makes an add split file content into blocks at
+// boundaries chosen by a rolling hash of the content, averaging avgSize
+// bytes. An insertion into the content then only changes the blocks around
+// it, so that successive versions of a file share most of their blocks.
+//
+// Like WithChunkSize, it makes the client import the content itself.
+func WithRabinChunking(avgSize int) AddOption {
+	return func(o *addOptions) {
+		if avgSize <= 0 || int64(avgSize/2*3) > importer.BlockSizeLimit {
+			o.err = errors.Errorf("invalid average chunk size %d", avgSize)
+			return
+		}
+		o.splitter = &rabinSplitter{chunk.NewMaybeRabin(avgSize)}
+	}
+}
+
+// rabinWindow is the size of the window chunk.MaybeRabin hashes over.
+const rabinWindow = 16
+
+// rabinSplitter wraps chunk.MaybeRabin, which never closes its output when
+// the content is shorter than its window.
+type rabinSplitter struct {
+	*chunk.MaybeRabin
+}
+
+func (s *rabinSplitter) Split(r io.Reader) chan []byte {
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(rabinWindow); err != nil {
+		// as with chunk.SizeSplitter, empty content has no blocks
+		out := make(chan []byte, 1)
+		if len(head) > 0 {
+			out <- head
+		}
+		close(out)
+		return out
+	}
+	return s.MaybeRabin.Split(br)
+}
@@ -1,95 +1,301 @@
 package interplanetary
 
 import (
-	"bytes"
 	"io"
 	"net/http"
+	gopath "path"
+	"time"
 
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
 	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
-	cmds_http "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands/http"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
 	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
-	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
-	ma "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multiaddr"
-	ma_net "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multiaddr-net"
+	chunk "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer/chunk"
+	peer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/peer"
 )
 
 type Client interface {
-	Add(io.Reader) (Key, error)
-	Cat(Key) (io.Reader, error)
+	Add(context.Context, io.Reader, ...AddOption) (Key, error)
+	AddDir(ctx context.Context, path string, opts ...AddOption) (Key, map[string]Key, error)
+	AddFiles(context.Context, cmds.File, ...AddOption) (Key, map[string]Key, error)
+	Cat(context.Context, Key) (io.ReadCloser, error)
+	CatRange(ctx context.Context, k Key, offset, length int64) (io.ReadCloser, error)
+	Ls(ctx context.Context, keysOrPaths ...string) ([]Object, error)
+
+	Pin(ctx context.Context, k Key, recursive bool) error
+	Unpin(ctx context.Context, k Key, recursive bool) error
+	Pins(context.Context, PinType) ([]Key, error)
+
+	Publish(ctx context.Context, name string, k Key) (*IpnsEntry, error)
+	Resolve(ctx context.Context, name string) (*IpnsEntry, error)
+
+	ObjectGet(context.Context, Key) (*Node, error)
+	ObjectPut(context.Context, *Node) (Key, error)
+	ObjectPutRaw(ctx context.Context, r io.Reader, enc ObjectEncoding) (Key, error)
+	ObjectData(context.Context, Key) (io.ReadCloser, error)
+	ObjectLinks(context.Context, Key) ([]Link, error)
+
+	BlockGet(context.Context, Key) (io.ReadCloser, error)
+	BlockPut(context.Context, io.Reader) (Key, error)
+	BlockStat(context.Context, Key) (*BlockStat, error)
+
+	Refs(ctx context.Context, k Key, opts ...RefsOption) (<-chan Key, <-chan error)
+
+	ID(ctx context.Context) (*PeerInfo, error)
+	PeerInfo(context.Context, peer.ID) (*PeerInfo, error)
+
+	SwarmPeers(ctx context.Context) ([]PeerAddr, error)
+	SwarmConnect(ctx context.Context, peers ...PeerAddr) error
+
+	Bootstrap(ctx context.Context) ([]PeerAddr, error)
+	BootstrapAdd(ctx context.Context, peers ...PeerAddr) ([]PeerAddr, error)
+	BootstrapRemove(ctx context.Context, peers ...PeerAddr) ([]PeerAddr, error)
+
+	Config(ctx context.Context, key string) (interface{}, error)
+	SetConfig(ctx context.Context, key, value string) error
+	ConfigShow(ctx context.Context) (*config.Config, error)
+	ApplyConfig(ctx context.Context, partial interface{}) error
+
+	Diagnostics(ctx context.Context) (*DiagnosticReport, error)
 
 	http.FileSystem
+	FS(ctx context.Context, timeout time.Duration) http.FileSystem
 }
 
+// client implements Client on top of a sender, which runs the commands.
 type client struct {
-	httpClient cmds_http.Client
+	sender
+}
+
+// sender runs requests built for the commands in core_cmds.Root.
+type sender interface {
+	// send runs req and returns its decoded output. Streamed output is an
+	// io.ReadCloser, closed when ctx is done.
+	send(ctx context.Context, req cmds.Request) (cmds.Response, error)
+	// stream runs req and returns its output undecoded, as the daemon
+	// encodes it. The output is closed when ctx is done.
+	stream(ctx context.Context, req cmds.Request) (io.ReadCloser, error)
 }
 
-func NewClient(addr string) (Client, error) {
-	// TODO test returns nil if addr is not a multiaddr
-	// TODO allow to connect with either multiaddr or other through configuration option
-	maddr, err := ma.NewMultiaddr(addr)
+// NewClient returns a client of the daemon listening on addr, configured
+// with opts. addr may be a multiaddr such as "/ip4/127.0.0.1/tcp/5001", a
+// "host:port" pair, an "http://host:port/prefix" URL or a unix socket given
+// as "unix:///path/to/socket".
+func NewClient(addr string, opts ...Option) (Client, error) {
+	a, err := parseAddr(addr)
 	if err != nil {
 		return nil, err
 	}
 
-	_, host, err := ma_net.DialArgs(maddr)
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	hc, scheme, err := o.client(a)
+	if err != nil {
+		return nil, err
+	}
+	return &client{&httpSender{
+		host:       a.host,
+		scheme:     scheme,
+		apiPath:    a.prefix + o.apiPath,
+		header:     o.header,
+		userAgent:  o.userAgent,
+		httpClient: hc,
+	}}, nil
+}
+
+func (c *client) Add(ctx context.Context, r io.Reader, opts ...AddOption) (Key, error) {
+	files, err := c.add(ctx, &cmds.ReaderFile{Filename: "", Reader: r}, opts)
 	if err != nil {
 		return nil, err
 	}
-	return &client{
-		httpClient: cmds_http.NewClient(host),
-	}, nil
+	return files[len(files)-1].key, nil
+}
+
+// AddDir recursively adds the directory at path. It returns the key of the
+// directory and the keys of every file and directory added, by name.
+func (c *client) AddDir(ctx context.Context, path string, opts ...AddOption) (Key, map[string]Key, error) {
+	f, err := newDiskFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.AddFiles(ctx, f, opts...)
+}
+
+// AddFiles adds f, which may be a file or a directory tree. It returns the
+// key of f and the keys of every file and directory added, by path: the base
+// name of f, followed by the names of the directories leading to the file,
+// such as "site/sub/page.html".
+func (c *client) AddFiles(ctx context.Context, f cmds.File, opts ...AddOption) (Key, map[string]Key, error) {
+	files, err := c.add(ctx, f, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make(map[string]Key, len(files))
+	for _, af := range files {
+		keys[af.name] = af.key
+	}
+	return files[len(files)-1].key, keys, nil
+}
+
+// AddOption changes how Add, AddDir and AddFiles add content.
+type AddOption func(*addOptions)
+
+type addOptions struct {
+	progress func(AddProgress)
+	splitter chunk.BlockSplitter
+	err      error
+}
+
+func newAddOptions(opts []AddOption) (*addOptions, error) {
+	o := new(addOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.err != nil {
+		return nil, o.err
+	}
+	return o, nil
+}
+
+// file wraps f as the options require before it is added.
+func (o *addOptions) file(f cmds.File) cmds.File {
+	if o.progress != nil {
+		return newProgressFile(f, o.progress)
+	}
+	return f
 }
 
-func (c *client) Add(r io.Reader) (Key, error) {
-	// SliceFile is a workaround for https://github.com/jbenet/go-ipfs/issues/392
-	// FIXME pass ReaderFile to NewRequest
-	f := &cmds.SliceFile{"TODO",
-		[]cmds.File{
-			&cmds.ReaderFile{Filename: "TODO", Reader: r},
-		},
+// split returns the splitter content is imported with.
+func (o *addOptions) split() chunk.BlockSplitter {
+	if o.splitter != nil {
+		return o.splitter
 	}
-	req, err := cmds.NewRequest([]string{"add"}, nil, nil, f, core_cmds.AddCmd, nil)
+	return chunk.DefaultSplitter
+}
+
+// addedFile is a file or directory added by an add, and its key.
+type addedFile struct {
+	name string // path of the file, as documented by AddFiles
+	key  Key
+}
+
+// add adds f. It returns every file and directory added, in the order the
+// daemon lists them: directories come after their contents, so f is last.
+func (c *client) add(ctx context.Context, f cmds.File, opts []AddOption) ([]addedFile, error) {
+	o, err := newAddOptions(opts)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.httpClient.Send(req)
+	f = o.file(f)
+	if o.splitter != nil {
+		return c.importFiles(ctx, f, o.splitter)
+	}
+
+	req, err := c.request([]string{"add"}, map[string]interface{}{
+		cmds.RecLong: f.IsDirectory(),
+	})
 	if err != nil {
 		return nil, err
 	}
-	switch v := res.Output().(type) {
-	case *core_cmds.AddOutput:
-		if len(v.Objects) < 1 {
-			return nil, errors.New("malformed response")
-		}
-		k, err := parseKey(v.Objects[0].Hash)
+	// the names the daemon lists may not be the paths sent, so the paths are
+	// recorded as the request is sent, in the order the daemon lists them
+	var paths []string
+	req.SetFiles(&cmds.SliceFile{Filename: "", Files: []cmds.File{
+		newPathFile(f, filePath("", f), &paths),
+	}})
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.AddOutput)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	if len(out.Objects) < 1 || len(out.Objects) != len(paths) {
+		return nil, errMalformed(res.Request(), "malformed response")
+	}
+	files := make([]addedFile, len(out.Objects))
+	for i, obj := range out.Objects {
+		k, err := ParseKey(obj.Hash)
 		if err != nil {
-			return nil, err
+			return nil, wrapMalformed(req, err)
 		}
-		return k, nil
-	default:
-		return nil, errors.New("unrecognized output format")
+		files[i] = addedFile{name: paths[i], key: k}
 	}
+	return files, nil
+}
+
+// filePath returns the path of f, a file of the directory at dir.
+func filePath(dir string, f cmds.File) string {
+	_, name := gopath.Split(f.FileName())
+	return gopath.Join(dir, name)
+}
+
+// pathFile wraps a cmds.File tree, appending the path of every file and
+// directory to paths as the tree is read: files when they are reached, and
+// directories once their contents are exhausted.
+type pathFile struct {
+	cmds.File
+	path  string
+	paths *[]string
+	done  bool
 }
 
-func (c *client) Cat(k Key) (io.Reader, error) {
-	// FIXME workaround for panic in http.Send
-	f := &cmds.SliceFile{"TODO",
-		[]cmds.File{
-			&cmds.ReaderFile{Filename: "TODO", Reader: bytes.NewReader([]byte(""))},
-		},
+func newPathFile(f cmds.File, path string, paths *[]string) *pathFile {
+	if !f.IsDirectory() {
+		*paths = append(*paths, path)
+	}
+	return &pathFile{File: f, path: path, paths: paths}
+}
+
+func (f *pathFile) NextFile() (cmds.File, error) {
+	child, err := f.File.NextFile()
+	if err == io.EOF && !f.done {
+		f.done = true
+		*f.paths = append(*f.paths, f.path)
 	}
-	req, err := cmds.NewRequest([]string{"cat"}, nil, []string{k.String()}, f, core_cmds.CatCmd, nil)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.httpClient.Send(req)
+	return newPathFile(child, filePath(f.path, child), f.paths), nil
+}
+
+// Cat returns the contents of the file named by k. The caller must close the
+// returned reader.
+func (c *client) Cat(ctx context.Context, k Key) (io.ReadCloser, error) {
+	req, err := c.request([]string{"cat"}, nil, k.String())
 	if err != nil {
 		return nil, err
 	}
-	return res.Reader()
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser(res)
 }
 
-func (c *client) Open(filename string) (http.File, error) {
-	return nil, errors.New("TODO")
+// request builds a request for the command found at path in the daemon's
+// command tree. Options are checked against the definitions of that command.
+func (c *client) request(path []string, opts map[string]interface{}, args ...string) (cmds.Request, error) {
+	return c.requestAs(nil, path, opts, args...)
+}
+
+// requestAs is like request, but the output of the command is decoded into
+// a value of the same type as typ. It allows decoding the output of commands
+// whose output type is unexported.
+func (c *client) requestAs(typ interface{}, path []string, opts map[string]interface{}, args ...string) (cmds.Request, error) {
+	cmd, err := core_cmds.Root.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	if typ != nil {
+		cmd = &cmds.Command{Arguments: cmd.Arguments, Type: typ}
+	}
+	optDefs, err := core_cmds.Root.GetOptions(path)
+	if err != nil {
+		return nil, err
+	}
+	return cmds.NewRequest(path, opts, args, nil, cmd, optDefs)
 }
@@ -0,0 +1,1056 @@
+package interplanetary_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	dag "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/merkledag"
+	ft "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/unixfs"
+	u "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util"
+	"github.com/maybebtc/interplanetary/interplanetarytest"
+)
+
+// newDaemon starts a stand-in daemon, closed at the end of the test.
+func newDaemon(t *testing.T) *interplanetarytest.Daemon {
+	d, err := interplanetarytest.NewDaemon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.Close)
+	return d
+}
+
+// newOnlineDaemon starts a stand-in daemon with an online node, bootstrapping
+// from peers, closed at the end of the test.
+func newOnlineDaemon(t *testing.T, peers ...*interplanetarytest.Daemon) *interplanetarytest.Daemon {
+	d, err := interplanetarytest.NewOnlineDaemon(peers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.Close)
+	return d
+}
+
+// newClient returns a client of the daemon at addr, configured with opts.
+func newClient(t *testing.T, addr string, opts ...ipfs.Option) ipfs.Client {
+	c, err := ipfs.NewClient(addr, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// serverAddr returns the multiaddr of the test server s.
+func serverAddr(t *testing.T, s *httptest.Server) string {
+	host, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "/ip4/" + host + "/tcp/" + port
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func readAll(t *testing.T, r interface {
+	Read([]byte) (int, error)
+	Close() error
+}) []byte {
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAddCat(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("interplanetary"), 50000)
+	k, err := c.Add(ctx, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ipfs.ComputeKey(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.Equal(want) {
+		t.Errorf("added as %s, want %s", k, want)
+	}
+
+	r, err := c.Cat(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, r); !bytes.Equal(got, data) {
+		t.Errorf("cat returned %d bytes, want %d", len(got), len(data))
+	}
+
+	objects, err := c.Ls(ctx, k.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Hash != k.String() || len(objects[0].Links) == 0 {
+		t.Errorf("unexpected listing: %+v", objects)
+	}
+}
+
+func TestAddMalformed(t *testing.T) {
+	c := stubClient(t, stubCommand(t, "add", nil, `{"Objects":[{"Hash":"notakey"}],"Names":["f"]}`))
+	_, err := c.Add(context.Background(), strings.NewReader("data"))
+	if e, ok := err.(*ipfs.Error); !ok || e.Kind != ipfs.ErrMalformedResponse {
+		t.Errorf("expected a malformed response error, got %#v", err)
+	}
+}
+
+func TestAddDir(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "interplanetary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "site")
+	for name, data := range map[string]string{
+		"index.html":    "<p>hello</p>",
+		"sub/page.html": "<p>page</p>",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	k, keys, err := c.AddDir(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 4 {
+		t.Errorf("expected keys for 4 files and directories, got %v", keys)
+	}
+	want, err := ipfs.ComputeDirKey(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.Equal(want) {
+		t.Errorf("added as %s, want %s", k, want)
+	}
+
+	// serve the directory through the client as an http.FileSystem
+	s := httptest.NewServer(http.FileServer(c))
+	defer s.Close()
+	res, err := http.Get(s.URL + "/" + k.String() + "/sub/page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res.Body); res.StatusCode != http.StatusOK || string(body) != "<p>page</p>" {
+		t.Errorf("got %s %q", res.Status, body)
+	}
+}
+
+// siteFiles returns an in-memory directory tree holding files of the same
+// name in different directories. Files are named by their base name only.
+func siteFiles() cmds.File {
+	dir := func(name string, files ...cmds.File) cmds.File {
+		return &cmds.SliceFile{Filename: name, Files: files}
+	}
+	file := func(name, data string) cmds.File {
+		return &cmds.ReaderFile{Filename: name, Reader: strings.NewReader(data)}
+	}
+	return dir("site",
+		dir("a", file("index.html", "a")),
+		dir("b", file("index.html", "b")),
+	)
+}
+
+func TestAddFilesPaths(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	k, keys, err := c.AddFiles(ctx, siteFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 5 || !keys["site"].Equal(k) {
+		t.Errorf("expected keys for 5 files and directories, rooted at %s, got %v", k, keys)
+	}
+	for _, name := range []string{"a", "b"} {
+		r, err := c.Cat(ctx, keys["site/"+name+"/index.html"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readAll(t, r); string(got) != name {
+			t.Errorf("site/%s/index.html contains %q", name, got)
+		}
+	}
+
+	// the daemon's add, the client's import and an embedded node name the
+	// added files alike
+	d := newDaemon(t)
+	cases := []struct {
+		name string
+		c    ipfs.Client
+		opts []ipfs.AddOption
+	}{
+		{"chunked", newClient(t, d.Addr), []ipfs.AddOption{ipfs.WithChunkSize(4096)}},
+		{"embedded", ipfs.NewNodeClient(d.Node, d.ConfigRoot), nil},
+	}
+	for _, tc := range cases {
+		_, got, err := tc.c.AddFiles(ctx, siteFiles(), tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, keys) {
+			t.Errorf("%s: added %v, want %v", tc.name, got, keys)
+		}
+	}
+}
+
+func TestLsDaemon(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	k, keys, err := c.AddFiles(ctx, siteFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := k.String() + "/a"
+	objects, err := c.Ls(ctx, k.String(), sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 || objects[0].Hash != k.String() || objects[1].Hash != sub {
+		t.Fatalf("unexpected listing: %+v", objects)
+	}
+	links := objects[0].Links
+	if len(links) != 2 || links[0].Name != "a" || !links[0].Hash.Equal(keys["site/a"]) || links[1].Name != "b" {
+		t.Errorf("%s: got links %+v", k, links)
+	}
+	links = objects[1].Links
+	if len(links) != 1 || links[0].Name != "index.html" || !links[0].Hash.Equal(keys["site/a/index.html"]) || links[0].Size == 0 {
+		t.Errorf("%s: got links %+v", sub, links)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	var requests int
+	c := newClient(t, newDaemon(t).Addr, ipfs.WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return http.DefaultTransport.RoundTrip(req)
+	})))
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "interplanetary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"empty", "sub"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range map[string]string{
+		"a.txt":     "a",
+		"zero":      "",
+		"sub/b.txt": "b",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	k, _, err := c.AddDir(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests = 0
+	f, err := c.Open("/" + k.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs := map[string]bool{}
+	for _, fi := range infos {
+		dirs[fi.Name()] = fi.IsDir()
+	}
+	want := map[string]bool{"a.txt": false, "empty": true, "sub": true, "zero": false}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("listed %v, want %v", dirs, want)
+	}
+	if requests != 3 {
+		t.Errorf("opened and listed the directory with %d requests, want 3", requests)
+	}
+
+	// missing paths are reported as such to http.FileServer
+	if _, err := c.Open("/" + k.String() + "/missing"); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+	s := httptest.NewServer(http.FileServer(c))
+	defer s.Close()
+	res, err := http.Get(s.URL + "/" + k.String() + "/sub/missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("got %s for a missing file", res.Status)
+	}
+}
+
+func TestReaddirBatches(t *testing.T) {
+	const (
+		dir     = "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK"
+		entries = 150
+	)
+	// a directory of files, each with a single block
+	links := make([]string, entries)
+	for i := range links {
+		links[i] = `{"Name":"f` + strconv.Itoa(i) + `","Hash":"` + u.Hash([]byte{byte(i)}).B58String() + `","Size":20}`
+	}
+	var batches int
+	c := stubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		args := r.URL.Query()["arg"]
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/object/data"):
+			w.Header().Set("X-Stream-Output", "1")
+			w.Write(ft.FolderPBData())
+		case len(args) == 1 && args[0] == dir:
+			w.Write([]byte(`{"Objects":[{"Hash":"` + dir + `","Links":[` + strings.Join(links, ",") + `]}]}`))
+		default:
+			batches++
+			if len(args) > 64 {
+				t.Errorf("listed %d entries in one request", len(args))
+			}
+			objects := make([]string, len(args))
+			for i, a := range args {
+				objects[i] = `{"Hash":"` + a + `","Links":[{"Name":"","Hash":"` + a + `","Size":10}]}`
+			}
+			w.Write([]byte(`{"Objects":[` + strings.Join(objects, ",") + `]}`))
+		}
+	})
+
+	f, err := c.Open("/" + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != entries || batches != 3 {
+		t.Fatalf("listed %d entries in %d batches", len(infos), batches)
+	}
+	for i, fi := range infos {
+		if fi.Name() != "f"+strconv.Itoa(i) || fi.IsDir() || fi.Size() != 0 {
+			t.Errorf("entry %d: got %s, dir %v, size %d", i, fi.Name(), fi.IsDir(), fi.Size())
+		}
+	}
+}
+
+func TestAddProgress(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "interplanetary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sizes := map[string]int{"a": 300000, "b": 10, "c": 0}
+	var total int64
+	for name, size := range sizes {
+		data := bytes.Repeat([]byte("x"), size)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		total += int64(size)
+	}
+
+	var last ipfs.AddProgress
+	_, _, err = c.AddDir(ctx, dir, ipfs.WithProgress(func(p ipfs.AddProgress) {
+		if p.Bytes < last.Bytes || p.Files < last.Files {
+			t.Errorf("progress went backwards: %+v after %+v", p, last)
+		}
+		last = p
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Bytes != total || last.Files != len(sizes) {
+		t.Errorf("last progress was %+v, want %d bytes in %d files", last, total, len(sizes))
+	}
+}
+
+func TestAddChunking(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 40000)
+	for _, opt := range []ipfs.AddOption{ipfs.WithChunkSize(4096), ipfs.WithRabinChunking(8192)} {
+		k, err := c.Add(ctx, bytes.NewReader(data), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ipfs.ComputeKey(bytes.NewReader(data), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !k.Equal(want) {
+			t.Errorf("added as %s, want %s", k, want)
+		}
+
+		r, err := c.Cat(ctx, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readAll(t, r); !bytes.Equal(got, data) {
+			t.Errorf("cat returned %d bytes, want %d", len(got), len(data))
+		}
+		pins, err := c.Pins(ctx, ipfs.RecursivePins)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsKey(pins, k) {
+			t.Errorf("%s not pinned: %v", k, pins)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "interplanetary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "dump.sql"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	k, keys, err := c.AddDir(ctx, dir, ipfs.WithRabinChunking(8192))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("expected keys for 2 files and directories, got %v", keys)
+	}
+	want, err := ipfs.ComputeDirKey(dir, ipfs.WithRabinChunking(8192))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.Equal(want) {
+		t.Errorf("added as %s, want %s", k, want)
+	}
+}
+
+func containsKey(keys []ipfs.Key, k ipfs.Key) bool {
+	for _, key := range keys {
+		if key.Equal(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCatRange(t *testing.T) {
+	var blockGets int
+	c := newClient(t, newDaemon(t).Addr, ipfs.WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/block/get") {
+			blockGets++
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})))
+	ctx := context.Background()
+
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	k, err := c.Add(ctx, bytes.NewReader(data), ipfs.WithChunkSize(4096))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct{ offset, length int64 }{
+		{0, -1},
+		{0, 10},
+		{4090, 10},
+		{8192, 4096},
+		{50000, -1},
+		{99999, 100},
+		{100000, -1},
+		{200000, 10},
+		{10, 0},
+	}
+	for _, tc := range cases {
+		r, err := c.CatRange(ctx, k, tc.offset, tc.length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := data[min(tc.offset, int64(len(data))):]
+		if tc.length >= 0 && tc.length < int64(len(want)) {
+			want = want[:tc.length]
+		}
+		if got := readAll(t, r); !bytes.Equal(got, want) {
+			t.Errorf("range %d+%d: got %d bytes, want %d", tc.offset, tc.length, len(got), len(want))
+		}
+	}
+
+	// the tail of the file takes the root and its last block only
+	blockGets = 0
+	r, err := c.CatRange(ctx, k, int64(len(data))-10, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, r); !bytes.Equal(got, data[len(data)-10:]) || blockGets != 2 {
+		t.Errorf("got %d bytes with %d block gets", len(got), blockGets)
+	}
+
+	if _, err := c.CatRange(ctx, k, -1, 10); err == nil {
+		t.Error("expected an error for a negative offset")
+	}
+
+	// ranged requests through the client as an http.FileSystem
+	s := httptest.NewServer(http.FileServer(c))
+	defer s.Close()
+	req, err := http.NewRequest("GET", s.URL+"/"+k.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Range", "bytes=-1000")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res.Body); res.StatusCode != http.StatusPartialContent || !bytes.Equal(body, data[len(data)-1000:]) {
+		t.Errorf("got %s with %d bytes", res.Status, len(body))
+	}
+
+	// a file inside a directory is ranged by its own key
+	dk, _, err := c.AddFiles(ctx, &cmds.SliceFile{Filename: "dir", Files: []cmds.File{
+		&cmds.ReaderFile{Filename: "data", Reader: bytes.NewReader(data)},
+	}}, ipfs.WithChunkSize(4096))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockGets = 0
+	req, err = http.NewRequest("GET", s.URL+"/"+dk.String()+"/data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Range", "bytes=-1000")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res.Body); res.StatusCode != http.StatusPartialContent || !bytes.Equal(body, data[len(data)-1000:]) || blockGets == 0 {
+		t.Errorf("got %s with %d bytes and %d block gets", res.Status, len(body), blockGets)
+	}
+
+	// cancelling the context stops the reader, even if it is not closed
+	cctx, cancel := context.WithCancel(ctx)
+	r, err = c.CatRange(cctx, k, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := ioutil.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCatRangeMalformed(t *testing.T) {
+	const k = "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK"
+	notFile, err := (&dag.Node{Data: []byte{0xff}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a block that is not a node, then a node that is not a file
+	for _, block := range []string{"\xff\xff", string(notFile)} {
+		c := stubClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Stream-Output", "1")
+			w.Write([]byte(block))
+		})
+		_, err := c.CatRange(context.Background(), mustParseKey(t, k), 0, -1)
+		e, ok := err.(*ipfs.Error)
+		if !ok || e.Kind != ipfs.ErrMalformedResponse {
+			t.Errorf("%q: expected a malformed response error, got %v", block, err)
+		}
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	// a daemon that stalls, after starting to stream the output of cat
+	stall := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cat") {
+			w.Header().Set("X-Stream-Output", "1")
+			w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-stall:
+		case <-r.Context().Done():
+		}
+	}))
+	defer s.Close()
+	defer close(stall)
+	c := newClient(t, serverAddr(t, s))
+	k, err := ipfs.ParseKey("QmWfVY9y3xjsixTgbd9AorQxH7VtMpzfx2HaWtsoUYecaX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(what string, start time.Time, err error) {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected context.DeadlineExceeded, got %v", what, err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("%s: returned after %s", what, d)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.BlockStat(ctx, k)
+	check("BlockStat", start, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	r, err := c.Cat(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if string(data) != "partial" {
+		t.Errorf("cat returned %q", data)
+	}
+	check("Cat", start, err)
+
+	start = time.Now()
+	_, err = c.FS(context.Background(), 50*time.Millisecond).Open("/" + k.String())
+	check("Open", start, err)
+}
+
+func TestFSTimeout(t *testing.T) {
+	// a daemon streaming a file in chunks, each well within the timeout
+	// but together longer than it
+	const chunks = 5
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Stream-Output", "1")
+		if strings.HasSuffix(r.URL.Path, "/object/data") {
+			w.Write(ft.FilePBData(nil, chunks))
+			return
+		}
+		for i := 0; i < chunks; i++ {
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer s.Close()
+	c := newClient(t, serverAddr(t, s))
+
+	f, err := c.FS(context.Background(), 50*time.Millisecond).Open("/QmWfVY9y3xjsixTgbd9AorQxH7VtMpzfx2HaWtsoUYecaX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// a reader slower than the timeout does not stall the file either
+	time.Sleep(60 * time.Millisecond)
+	data, err := ioutil.ReadAll(f)
+	if err != nil || string(data) != "xxxxx" {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
+
+func TestPins(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	k, err := c.Add(ctx, bytes.NewReader([]byte("pinned")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins, err := c.Pins(ctx, ipfs.RecursivePins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || !pins[0].Equal(k) {
+		t.Errorf("added key is not pinned: %v", pins)
+	}
+	if err := c.Unpin(ctx, k, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Pin(ctx, k, false); err != nil {
+		t.Fatal(err)
+	}
+	pins, err = c.Pins(ctx, ipfs.DirectPins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || !pins[0].Equal(k) {
+		t.Errorf("key is not pinned directly: %v", pins)
+	}
+}
+
+func TestNamesDaemon(t *testing.T) {
+	d := newOnlineDaemon(t)
+	c := newClient(t, d.Addr)
+	ctx := context.Background()
+
+	k, err := c.Add(ctx, bytes.NewReader([]byte("published")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := c.Publish(ctx, "", k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name != d.Node.Identity.ID().Pretty() || entry.Value != k.String() {
+		t.Errorf("publish: got %+v", entry)
+	}
+	if _, err := c.Publish(ctx, entry.Name, k); err != nil {
+		t.Errorf("publish at the node's own name: %v", err)
+	}
+	for _, name := range []string{"", entry.Name} {
+		resolved, err := c.Resolve(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resolved.Name != entry.Name || resolved.Value != k.String() {
+			t.Errorf("resolve %q: got %+v, want %+v", name, resolved, entry)
+		}
+	}
+}
+
+func TestPeerInfoDaemon(t *testing.T) {
+	a := newOnlineDaemon(t)
+	b := newOnlineDaemon(t, a)
+	ca, cb := newClient(t, a.Addr), newClient(t, b.Addr)
+	ctx := context.Background()
+
+	check := func(what string, info *ipfs.PeerInfo) {
+		if !info.ID.Equal(a.Node.Identity.ID()) {
+			t.Errorf("%s: got id %s", what, info.ID.Pretty())
+		}
+		if len(info.Addresses) != 1 || info.Addresses[0].String() != a.Node.Config.Addresses.Swarm[0] {
+			t.Errorf("%s: got addresses %v", what, info.Addresses)
+		}
+	}
+	self, err := ca.ID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("id", self)
+	if h, err := self.PublicKey.Hash(); err != nil || !bytes.Equal(h, self.ID) {
+		t.Errorf("id: public key does not match the id")
+	}
+	if self.AgentVersion == "" || self.ProtocolVersion == "" {
+		t.Errorf("id: got versions %q and %q", self.AgentVersion, self.ProtocolVersion)
+	}
+
+	// b finds a once it has bootstrapped from it
+	var info *ipfs.PeerInfo
+	for start := time.Now(); info == nil; time.Sleep(10 * time.Millisecond) {
+		info, err = cb.PeerInfo(ctx, self.ID)
+		if err != nil && time.Since(start) > 5*time.Second {
+			t.Fatal(err)
+		}
+	}
+	check("peer info", info)
+}
+
+func TestSwarmDaemon(t *testing.T) {
+	a, b := newOnlineDaemon(t), newOnlineDaemon(t)
+	ca, cb := newClient(t, a.Addr), newClient(t, b.Addr)
+	ctx := context.Background()
+
+	peers, err := cb.SwarmPeers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 0 {
+		t.Errorf("expected no peers before connecting, got %v", peers)
+	}
+	p, err := ipfs.ParsePeerAddr(a.Node.Config.Addresses.Swarm[0] + "/" + a.Node.Identity.ID().Pretty())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cb.SwarmConnect(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err = cb.SwarmPeers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].String() != p.String() {
+		t.Errorf("peers of b: got %v, want %v", peers, p)
+	}
+	peers, err = ca.SwarmPeers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || !peers[0].ID.Equal(b.Node.Identity.ID()) {
+		t.Errorf("peers of a: got %v", peers)
+	}
+}
+
+func TestDiagnosticsDaemon(t *testing.T) {
+	a, b := newOnlineDaemon(t), newOnlineDaemon(t)
+	ca := newClient(t, a.Addr)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	p, err := ipfs.ParsePeerAddr(b.Node.Config.Addresses.Swarm[0] + "/" + b.Node.Identity.ID().Pretty())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.SwarmConnect(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	// the daemon reports uptimes in whole seconds
+	time.Sleep(time.Second)
+
+	report, err := ca.Diagnostics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ida, idb := a.Node.Identity.ID().Pretty(), b.Node.Identity.ID().Pretty()
+	peers := make(map[string]ipfs.DiagnosticPeer)
+	for _, p := range report.Peers {
+		peers[p.ID] = p
+	}
+	for id, other := range map[string]string{ida: idb, idb: ida} {
+		info, ok := peers[id]
+		if !ok {
+			t.Errorf("%s missing from the report %+v", id, report)
+			continue
+		}
+		if info.Uptime < time.Second {
+			t.Errorf("%s: got uptime %s", id, info.Uptime)
+		}
+		if len(info.Connections) != 1 || info.Connections[0].ID != other {
+			t.Errorf("%s: got connections %+v, want one to %s", id, info.Connections, other)
+		}
+	}
+}
+
+func TestObjects(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	child, err := c.BlockPut(ctx, bytes.NewReader([]byte("child")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := c.BlockStat(ctx, child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stat.Key.Equal(child) || stat.Length != len("child") {
+		t.Errorf("block stat: got %+v", stat)
+	}
+	r, err := c.BlockGet(ctx, child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := readAll(t, r); string(data) != "child" {
+		t.Errorf("block get: got %q", data)
+	}
+
+	k, err := c.ObjectPut(ctx, &ipfs.Node{
+		Data:  []byte("parent"),
+		Links: []ipfs.Link{{Name: "child", Hash: child}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := c.ObjectGet(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(n.Data) != "parent" || len(n.Links) != 1 || !n.Links[0].Hash.Equal(child) {
+		t.Errorf("object get: got %+v", n)
+	}
+	r, err = c.ObjectData(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := readAll(t, r); string(data) != "parent" {
+		t.Errorf("object data: got %q", data)
+	}
+	links, err := c.ObjectLinks(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].Name != "child" {
+		t.Errorf("object links: got %+v", links)
+	}
+
+	keys, errs := c.Refs(ctx, k)
+	var refs []ipfs.Key
+	for ref := range keys {
+		refs = append(refs, ref)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || !refs[0].Equal(child) {
+		t.Errorf("refs: got %v", refs)
+	}
+}
+
+func TestRefsDaemon(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	// two identical directories, each holding the same file
+	same := func(name string) cmds.File {
+		return &cmds.SliceFile{Filename: name, Files: []cmds.File{
+			&cmds.ReaderFile{Filename: "f", Reader: strings.NewReader("same")},
+		}}
+	}
+	k, keys, err := c.AddFiles(ctx, &cmds.SliceFile{Filename: "d", Files: []cmds.File{same("x"), same("y")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, file := keys["d/x"], keys["d/x/f"]
+
+	cases := []struct {
+		opts []ipfs.RefsOption
+		want []ipfs.Key
+	}{
+		{nil, []ipfs.Key{dir, dir}},
+		{[]ipfs.RefsOption{ipfs.Unique}, []ipfs.Key{dir}},
+		{[]ipfs.RefsOption{ipfs.Recursive}, []ipfs.Key{dir, file, dir, file}},
+		{[]ipfs.RefsOption{ipfs.Recursive, ipfs.Unique}, []ipfs.Key{dir, file}},
+	}
+	for _, tc := range cases {
+		keys, errs := c.Refs(ctx, k, tc.opts...)
+		var refs []ipfs.Key
+		for ref := range keys {
+			refs = append(refs, ref)
+		}
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(refs, tc.want) {
+			t.Errorf("refs %v: got %v, want %v", tc.opts, refs, tc.want)
+		}
+	}
+}
+
+func TestConfigDaemon(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	if err := c.SetConfig(ctx, "Tour.Last", "config"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Config(ctx, "Tour.Last")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "config" {
+		t.Errorf("config: got %#v", v)
+	}
+	err = c.ApplyConfig(ctx, struct{ Datastore struct{ Path string } }{
+		Datastore: struct{ Path string }{Path: "/tmp/datastore"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := c.ConfigShow(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Tour.Last != "config" || cfg.Datastore.Path != "/tmp/datastore" {
+		t.Errorf("config show: got %+v", cfg)
+	}
+}
+
+func TestBootstrapDaemon(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	ctx := context.Background()
+
+	p, err := ipfs.ParsePeerAddr("/ip4/104.131.131.82/tcp/4001/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := c.BootstrapAdd(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || added[0].String() != p.String() {
+		t.Errorf("bootstrap add: got %v", added)
+	}
+	peers, err := c.Bootstrap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].String() != p.String() {
+		t.Errorf("bootstrap: got %v", peers)
+	}
+
+	removed, err := c.BootstrapRemove(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].String() != p.String() {
+		t.Errorf("bootstrap rm: got %v", removed)
+	}
+	peers, err = c.Bootstrap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 0 {
+		t.Errorf("bootstrap after rm: got %v", peers)
+	}
+}
+
+func TestOfflineCommand(t *testing.T) {
+	c := newClient(t, newDaemon(t).Addr)
+	_, err := c.Resolve(context.Background(), "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
+	e, ok := err.(*ipfs.Error)
+	if !ok || e.Kind != ipfs.ErrDaemon || len(e.Path) != 2 || e.Path[1] != "resolve" {
+		t.Errorf("expected a daemon error, got %#v", err)
+	}
+}
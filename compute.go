@@ -0,0 +1,104 @@
+package interplanetary
+
+import (
+	"io"
+	gopath "path"
+
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	importer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer"
+	chunk "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer/chunk"
+	dag "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/merkledag"
+	ft "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/unixfs"
+)
+
+// ComputeKey returns the key Add would return for the data read from r,
+// given the same options, without contacting the daemon.
+func ComputeKey(r io.Reader, opts ...AddOption) (Key, error) {
+	return computeKey(&cmds.ReaderFile{Filename: "", Reader: r}, opts)
+}
+
+// ComputeDirKey returns the key AddDir would return for the file or directory
+// at path, given the same options, without contacting the daemon.
+func ComputeDirKey(path string, opts ...AddOption) (Key, error) {
+	f, err := newDiskFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return computeKey(f, opts)
+}
+
+func computeKey(f cmds.File, opts []AddOption) (Key, error) {
+	o, err := newAddOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	n, err := fileNode(o.file(f), filePath("", f), o.split(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nodeKey(n)
+}
+
+// fileNode builds the DAG of f the way the daemon's add command does,
+// splitting file content with spl. The DAG is built in memory, unless ds is
+// given, in which case nodes are added to ds as they are built. Every file
+// and directory is appended to added, if given, after its contents, named by
+// its path; path is the path of f.
+func fileNode(f cmds.File, path string, spl chunk.BlockSplitter, ds dag.DAGService, added *[]addedFile) (*dag.Node, error) {
+	var n *dag.Node
+	var err error
+	if f.IsDirectory() {
+		n, err = dirNode(f, path, spl, ds, added)
+	} else if ds != nil {
+		n, err = importer.BuildDagFromReader(f, ds, nil, spl)
+	} else {
+		n, err = importer.NewDagFromReaderWithSplitter(f, spl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if added != nil {
+		k, err := nodeKey(n)
+		if err != nil {
+			return nil, err
+		}
+		*added = append(*added, addedFile{name: path, key: k})
+	}
+	return n, nil
+}
+
+func dirNode(f cmds.File, path string, spl chunk.BlockSplitter, ds dag.DAGService, added *[]addedFile) (*dag.Node, error) {
+	tree := &dag.Node{Data: ft.FolderPBData()}
+	for {
+		child, err := f.NextFile()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		n, err := fileNode(child, filePath(path, child), spl, ds, added)
+		if err != nil {
+			return nil, err
+		}
+		_, name := gopath.Split(child.FileName())
+		if err := tree.AddNodeLinkClean(name, n); err != nil {
+			return nil, err
+		}
+	}
+	if ds != nil {
+		if _, err := ds.Add(tree); err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
+}
+
+func nodeKey(n *dag.Node) (Key, error) {
+	h, err := n.Multihash()
+	if err != nil {
+		return nil, err
+	}
+	return KeyFromMultihash(h)
+}
@@ -0,0 +1,82 @@
+package interplanetary
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	chunk "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer/chunk"
+)
+
+func TestComputeKey(t *testing.T) {
+	// keys assigned by the daemon to the same data
+	cases := []struct {
+		data []byte
+		key  string
+	}{
+		{nil, "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"},
+		{[]byte("hello"), "QmWfVY9y3xjsixTgbd9AorQxH7VtMpzfx2HaWtsoUYecaX"},
+		{bytes.Repeat([]byte("abcdefgh"), 200000), "QmSuJZ2RfQrc9X8d5R4zP8gNmoJvF37TmdFMvTKFJ1cz1T"},
+	}
+	for _, c := range cases {
+		k, err := ComputeKey(bytes.NewReader(c.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.String() != c.key {
+			t.Errorf("%d bytes: got %s, want %s", len(c.data), k, c.key)
+		}
+	}
+}
+
+func TestComputeKeyChunking(t *testing.T) {
+	// content-defined blocks: a prefix shifts the content without changing
+	// most of the blocks after it
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+	shifted := append([]byte("prefix"), data...)
+
+	a, err := fileNode(&cmds.ReaderFile{Reader: bytes.NewReader(data)}, "", &rabinSplitter{chunk.NewMaybeRabin(8192)}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := fileNode(&cmds.ReaderFile{Reader: bytes.NewReader(shifted)}, "", &rabinSplitter{chunk.NewMaybeRabin(8192)}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := make(map[string]bool)
+	for _, l := range a.Links {
+		shared[l.Hash.B58String()] = true
+	}
+	n := 0
+	for _, l := range b.Links {
+		if shared[l.Hash.B58String()] {
+			n++
+		}
+	}
+	if n < len(a.Links)*9/10 {
+		t.Errorf("only %d of %d blocks shared", n, len(a.Links))
+	}
+
+	// short content does not fill the rolling hash window
+	for _, data := range [][]byte{nil, []byte("hello")} {
+		k, err := ComputeKey(bytes.NewReader(data), WithRabinChunking(8192))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ComputeKey(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !k.Equal(want) {
+			t.Errorf("%q: got %s, want %s", data, k, want)
+		}
+	}
+
+	for _, opt := range []AddOption{WithChunkSize(0), WithChunkSize(2 << 20), WithRabinChunking(-1)} {
+		if _, err := ComputeKey(bytes.NewReader(data), opt); err == nil {
+			t.Error("expected an error for an invalid chunk size")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package interplanetary
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// Config returns the value of the config entry at key, e.g. "Addresses.API".
+// The value is decoded from JSON.
+func (c *client) Config(ctx context.Context, key string) (interface{}, error) {
+	f, err := c.config(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return f.Value, nil
+}
+
+// SetConfig sets the config entry at key to value. The daemon stores every
+// value set this way as a string.
+func (c *client) SetConfig(ctx context.Context, key, value string) error {
+	_, err := c.config(ctx, key, value)
+	return err
+}
+
+func (c *client) config(ctx context.Context, args ...string) (*core_cmds.ConfigField, error) {
+	req, err := c.request([]string{"config"}, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.ConfigField)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	return out, nil
+}
+
+// ConfigShow returns the daemon's whole configuration.
+func (c *client) ConfigShow(ctx context.Context) (*config.Config, error) {
+	req, err := c.request([]string{"config", "show"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := res.Reader()
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := r.(io.Closer); ok {
+		defer rc.Close()
+	}
+	var cfg config.Config
+	if err := config.Decode(r, &cfg); err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	return &cfg, nil
+}
+
+// ApplyConfig sets each non-zero field of partial as its own config entry.
+// partial is a struct shaped like config.Config, or a subset of it, and
+// nested fields are addressed by their dotted path. As the daemon stores
+// every value as a string, fields whose JSON encoding is not a string are
+// rejected before any entry is set.
+func (c *client) ApplyConfig(ctx context.Context, partial interface{}) error {
+	updates := make(map[string]string)
+	if err := configUpdates("", reflect.ValueOf(partial), updates); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(updates))
+	for k := range updates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := c.SetConfig(ctx, k, updates[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configUpdates collects the non-zero leaves of v into updates, keyed by
+// their dotted path below prefix.
+func configUpdates(prefix string, v reflect.Value, updates map[string]string) error {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return configUpdates(prefix, v.Elem(), updates)
+	case reflect.Struct:
+		if _, ok := v.Interface().(json.Marshaler); ok {
+			break // encoded as a single value, e.g. time.Time
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue // unexported
+			}
+			name := f.Name
+			if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			if err := configUpdates(name, v.Field(i), updates); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface()) {
+		return nil
+	}
+	if prefix == "" {
+		return errors.New("config: partial must be a struct")
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.Errorf("config: cannot set %s: only string values are supported", prefix)
+	}
+	updates[prefix] = s
+	return nil
+}
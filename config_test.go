@@ -0,0 +1,74 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+)
+
+func TestConfig(t *testing.T) {
+	ctx := context.Background()
+	const api = "/ip4/127.0.0.1/tcp/5001"
+	mux := http.NewServeMux()
+	mux.Handle("/api/v0/config", stubCommand(t, "config", []string{"Addresses.API"}, `{"Key":"Addresses.API","Value":"`+api+`"}`))
+	mux.Handle("/api/v0/config/show", stubCommand(t, "config/show", nil, `{"Addresses":{"API":"`+api+`"}}`))
+	c := stubClient(t, mux.ServeHTTP)
+
+	v, err := c.Config(ctx, "Addresses.API")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != api {
+		t.Errorf("config: got %v", v)
+	}
+	cfg, err := c.ConfigShow(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addresses.API != api {
+		t.Errorf("config show: got %+v", cfg.Addresses)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	ctx := context.Background()
+	var sets [][]string
+	c := stubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		args := r.URL.Query()["arg"]
+		if r.URL.Path != "/api/v0/config" || len(args) != 2 {
+			t.Errorf("unexpected request %s %v", r.URL.Path, args)
+			http.NotFound(w, r)
+			return
+		}
+		sets = append(sets, args)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Key":"` + args[0] + `","Value":"` + args[1] + `"}`))
+	})
+
+	err := c.ApplyConfig(ctx, &config.Config{
+		Addresses: config.Addresses{API: "/ip4/127.0.0.1/tcp/5001"},
+		Datastore: config.Datastore{Type: "leveldb", Path: "/tmp/datastore"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Addresses.API", "/ip4/127.0.0.1/tcp/5001"},
+		{"Datastore.Path", "/tmp/datastore"},
+		{"Datastore.Type", "leveldb"},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Errorf("got %v, want %v", sets, want)
+	}
+
+	sets = nil
+	if err := c.ApplyConfig(ctx, &config.Config{Addresses: config.Addresses{Swarm: []string{"/ip4/0.0.0.0/tcp/4001"}}}); err == nil {
+		t.Error("expected an error for a non-string value")
+	}
+	if len(sets) != 0 {
+		t.Errorf("entries set before the error: %v", sets)
+	}
+}
@@ -0,0 +1,152 @@
+package interplanetary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+)
+
+// DiagnosticReport is a network diagnostics report: every node reached in
+// the network and the connections between them.
+type DiagnosticReport struct {
+	Peers []DiagnosticPeer
+}
+
+// DiagnosticPeer describes a node in a diagnostics report.
+type DiagnosticPeer struct {
+	ID           string
+	Uptime       time.Duration
+	BandwidthIn  uint64
+	BandwidthOut uint64
+	Connections  []DiagnosticConnection
+}
+
+// DiagnosticConnection is an open connection from a node to the peer ID.
+type DiagnosticConnection struct {
+	ID      string
+	Latency time.Duration
+}
+
+// Diagnostics generates a network diagnostics report. It may take the
+// daemon some time to collect.
+func (c *client) Diagnostics(ctx context.Context) (*DiagnosticReport, error) {
+	req, err := c.request([]string{"diag", "net"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.DiagnosticOutput)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+
+	report := &DiagnosticReport{Peers: make([]DiagnosticPeer, len(out.Peers))}
+	for i, p := range out.Peers {
+		conns := make([]DiagnosticConnection, len(p.Connections))
+		for j, conn := range p.Connections {
+			conns[j] = DiagnosticConnection{
+				ID:      conn.ID,
+				Latency: time.Duration(conn.NanosecondsLatency),
+			}
+		}
+		report.Peers[i] = DiagnosticPeer{
+			ID:           p.ID,
+			Uptime:       time.Duration(p.UptimeSeconds) * time.Second,
+			BandwidthIn:  p.BandwidthBytesIn,
+			BandwidthOut: p.BandwidthBytesOut,
+			Connections:  conns,
+		}
+	}
+	return report, nil
+}
+
+// edges returns the connections of the report between nodes it contains,
+// as pairs of indices into Peers. A connection reported by both ends is
+// returned once.
+func (r *DiagnosticReport) edges() [][2]int {
+	index := make(map[string]int, len(r.Peers))
+	for i, p := range r.Peers {
+		index[p.ID] = i
+	}
+	seen := make(map[[2]int]bool)
+	var edges [][2]int
+	for i, p := range r.Peers {
+		for _, conn := range p.Connections {
+			j, ok := index[conn.ID]
+			if !ok || seen[[2]int{j, i}] || seen[[2]int{i, j}] {
+				continue
+			}
+			seen[[2]int{i, j}] = true
+			edges = append(edges, [2]int{i, j})
+		}
+	}
+	return edges
+}
+
+// WriteDot writes the network topology to w as an undirected Graphviz DOT
+// graph. Edges are labelled with their latency.
+func (r *DiagnosticReport) WriteDot(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "graph swarm {"); err != nil {
+		return err
+	}
+	for _, p := range r.Peers {
+		if _, err := fmt.Fprintf(w, "\t%q;\n", p.ID); err != nil {
+			return err
+		}
+	}
+	for _, e := range r.edges() {
+		from := r.Peers[e[0]]
+		to := r.Peers[e[1]]
+		var latency time.Duration
+		for _, conn := range from.Connections {
+			if conn.ID == to.ID {
+				latency = conn.Latency
+				break
+			}
+		}
+		if _, err := fmt.Fprintf(w, "\t%q -- %q [label=%q];\n", from.ID, to.ID, latency.String()); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// d3Node and d3Link mirror the graph produced by the daemon's diagnostics
+// visualization, for use with a D3 force layout.
+type d3Node struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+type d3Link struct {
+	Source int `json:"source"`
+	Target int `json:"target"`
+	Value  int `json:"value"`
+}
+
+// WriteD3JSON writes the network topology to w as a D3 force layout graph,
+// in the same shape as the daemon's diagnostics visualization. Nodes are
+// weighted by their total bandwidth.
+func (r *DiagnosticReport) WriteD3JSON(w io.Writer) error {
+	nodes := make([]d3Node, len(r.Peers))
+	for i, p := range r.Peers {
+		nodes[i] = d3Node{Name: p.ID, Value: p.BandwidthIn + p.BandwidthOut}
+	}
+	edges := r.edges()
+	links := make([]d3Link, len(edges))
+	for i, e := range edges {
+		links[i] = d3Link{Source: e[0], Target: e[1], Value: 3}
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"nodes": nodes,
+		"links": links,
+	})
+}
@@ -0,0 +1,64 @@
+package interplanetary
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+var testReport = &DiagnosticReport{
+	Peers: []DiagnosticPeer{
+		{
+			ID:          "QmA",
+			BandwidthIn: 1,
+			Connections: []DiagnosticConnection{
+				{ID: "QmB", Latency: 2 * time.Millisecond},
+				{ID: "QmUnknown", Latency: time.Second},
+			},
+		},
+		{
+			ID:           "QmB",
+			BandwidthOut: 2,
+			Connections: []DiagnosticConnection{
+				{ID: "QmA", Latency: 3 * time.Millisecond},
+			},
+		},
+	},
+}
+
+func TestDiagnosticReportWriteDot(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `graph swarm {
+	"QmA";
+	"QmB";
+	"QmA" -- "QmB" [label="2ms"];
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestDiagnosticReportWriteD3JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport.WriteD3JSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var graph struct {
+		Nodes []d3Node `json:"nodes"`
+		Links []d3Link `json:"links"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 2 || graph.Nodes[0].Name != "QmA" || graph.Nodes[1].Value != 2 {
+		t.Fatalf("unexpected nodes: %v", graph.Nodes)
+	}
+	if len(graph.Links) != 1 || graph.Links[0].Source != 0 || graph.Links[0].Target != 1 {
+		t.Fatalf("unexpected links: %v", graph.Links)
+	}
+}
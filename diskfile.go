@@ -0,0 +1,105 @@
+package interplanetary
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+)
+
+// diskFile is a cmds.File backed by a file or directory on disk. Files are
+// opened on first read and closed once read to the end, so that large trees
+// can be sent without holding a descriptor open for every file.
+type diskFile struct {
+	name string // name sent to the daemon, relative to the added root
+	path string // location on disk
+	info os.FileInfo
+
+	f        *os.File
+	eof      bool
+	children []os.FileInfo
+	listed   bool
+}
+
+func newDiskFile(path string) (*diskFile, error) {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &diskFile{name: filepath.Base(path), path: path, info: info}, nil
+}
+
+func (f *diskFile) FileName() string {
+	return f.name
+}
+
+func (f *diskFile) IsDirectory() bool {
+	return f.info.IsDir()
+}
+
+func (f *diskFile) NextFile() (cmds.File, error) {
+	if !f.IsDirectory() {
+		return nil, cmds.ErrNotDirectory
+	}
+	if !f.listed {
+		children, err := ioutil.ReadDir(f.path)
+		if err != nil {
+			return nil, err
+		}
+		f.children = children
+		f.listed = true
+	}
+	if len(f.children) == 0 {
+		return nil, io.EOF
+	}
+	child := f.children[0]
+	f.children = f.children[1:]
+
+	path := filepath.Join(f.path, child.Name())
+	info, err := os.Stat(path) // follow symlinks
+	if err != nil {
+		return nil, err
+	}
+	return &diskFile{
+		name: f.name + "/" + child.Name(),
+		path: path,
+		info: info,
+	}, nil
+}
+
+func (f *diskFile) Read(p []byte) (int, error) {
+	if f.IsDirectory() {
+		return 0, cmds.ErrNotReader
+	}
+	if f.eof {
+		return 0, io.EOF
+	}
+	if f.f == nil {
+		file, err := os.Open(f.path)
+		if err != nil {
+			return 0, err
+		}
+		f.f = file
+	}
+	n, err := f.f.Read(p)
+	if err == io.EOF {
+		f.eof = true
+		f.Close()
+	}
+	return n, err
+}
+
+func (f *diskFile) Close() error {
+	if f.IsDirectory() {
+		return cmds.ErrNotReader
+	}
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
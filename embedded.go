@@ -0,0 +1,159 @@
+package interplanetary
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+	core "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// EmbeddedConfig configures a node started by NewEmbeddedClient.
+type EmbeddedConfig struct {
+	// Config is the configuration of the node, as written by 'ipfs init'.
+	Config *config.Config
+
+	// ConfigRoot is the directory holding the config file. The config and
+	// bootstrap commands read and write that file, and fail when ConfigRoot
+	// is empty.
+	ConfigRoot string
+
+	// Online connects the node to the network. An offline node only serves
+	// what is in its datastore.
+	Online bool
+}
+
+// EmbeddedClient is a Client running commands on a node in the current
+// process, without a daemon.
+type EmbeddedClient struct {
+	Client
+	node *core.IpfsNode
+}
+
+// NewEmbeddedClient starts a node configured by cfg and returns a client of
+// it. The node runs until the client is closed.
+func NewEmbeddedClient(cfg EmbeddedConfig) (*EmbeddedClient, error) {
+	n, err := core.NewIpfsNode(cfg.Config, cfg.Online)
+	if err != nil {
+		return nil, err
+	}
+	return NewNodeClient(n, cfg.ConfigRoot), nil
+}
+
+// NewNodeClient returns a client of n, a node already running in the current
+// process. configRoot is as in EmbeddedConfig.
+func NewNodeClient(n *core.IpfsNode, configRoot string) *EmbeddedClient {
+	return &EmbeddedClient{
+		Client: &client{newLocalSender(n, configRoot, n.Config)},
+		node:   n,
+	}
+}
+
+// Node returns the node the client runs commands on.
+func (c *EmbeddedClient) Node() *core.IpfsNode {
+	return c.node
+}
+
+// Close stops the node.
+func (c *EmbeddedClient) Close() error {
+	if c.node.ContextCloser == nil {
+		// nodes made by core.NewMockNode have nothing to stop
+		return nil
+	}
+	return c.node.Close()
+}
+
+// localSender runs requests by calling core_cmds.Root directly, on a node in
+// the current process.
+type localSender struct {
+	cctx cmds.Context
+}
+
+func newLocalSender(n *core.IpfsNode, configRoot string, cfg *config.Config) *localSender {
+	return &localSender{cmds.Context{
+		Online:     n.OnlineMode(),
+		ConfigRoot: configRoot,
+		LoadConfig: func(root string) (*config.Config, error) {
+			if root == "" {
+				return cfg, nil
+			}
+			filename, err := config.Filename(root)
+			if err != nil {
+				return nil, err
+			}
+			return config.Load(filename)
+		},
+		ConstructNode: func() (*core.IpfsNode, error) {
+			return n, nil
+		},
+	}}
+}
+
+func (s *localSender) send(ctx context.Context, req cmds.Request) (cmds.Response, error) {
+	out, stream, err := s.run(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res := cmds.NewResponse(req)
+	if stream {
+		res.SetOutput(out)
+		return res, nil
+	}
+
+	defer out.Close()
+	v, err := decodeOutput(out, req.Command().Type)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	res.SetOutput(v)
+	return res, nil
+}
+
+func (s *localSender) stream(ctx context.Context, req cmds.Request) (io.ReadCloser, error) {
+	out, _, err := s.run(ctx, req)
+	return out, err
+}
+
+// run calls the command of req and returns its output, encoded as the daemon
+// would send it, and whether the output is a raw stream. Marshalled outputs
+// go through JSON so that they decode to the same types as the daemon's.
+func (s *localSender) run(ctx context.Context, req cmds.Request) (io.ReadCloser, bool, error) {
+	path := req.Path()
+	if s.cctx.ConfigRoot == "" && (path[0] == "config" || path[0] == "bootstrap") {
+		return nil, false, &Error{Kind: ErrClient, Path: path, Message: "no config root"}
+	}
+	req.SetContext(s.cctx)
+
+	// commands cannot be interrupted, so a cancelled call is left to
+	// finish by itself
+	done := make(chan cmds.Response, 1)
+	go func() { done <- core_cmds.Root.Call(req) }()
+	var res cmds.Response
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		return nil, false, errDone(req, ctx)
+	}
+	if e := res.Error(); e != nil {
+		return nil, false, &Error{Kind: errorKind(e.Code, e.Message), Path: path, Message: e.Message}
+	}
+
+	if r, ok := res.Output().(io.Reader); ok {
+		rc, ok := r.(io.ReadCloser)
+		if !ok {
+			rc = ioutil.NopCloser(r)
+		}
+		return newCtxBody(ctx, req, rc), true, nil
+	}
+	data, err := json.Marshal(res.Output())
+	if err != nil {
+		return nil, false, errors.Wrap(err)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), false, nil
+}
@@ -0,0 +1,95 @@
+package interplanetary
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+)
+
+func newEmbeddedClient(t *testing.T) *EmbeddedClient {
+	root, err := ioutil.TempDir("", "interplanetary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	cfg := &config.Config{
+		Identity:  config.Identity{PeerID: "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ"},
+		Datastore: config.Datastore{Type: "memory"},
+	}
+	filename, err := config.Filename(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.WriteConfigFile(filename, cfg); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewEmbeddedClient(EmbeddedConfig{Config: cfg, ConfigRoot: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestEmbeddedClient(t *testing.T) {
+	c := newEmbeddedClient(t)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("embedded"), 100000)
+	k, err := c.Add(ctx, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ComputeKey(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.Equal(want) {
+		t.Errorf("added as %s, want %s", k, want)
+	}
+
+	r, err := c.Cat(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("cat returned different data")
+	}
+
+	objects, err := c.Ls(ctx, k.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || len(objects[0].Links) != 3 {
+		t.Errorf("unexpected listing: %+v", objects)
+	}
+
+	if err := c.SetConfig(ctx, "Tour.Last", "embedded"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Config(ctx, "Tour.Last")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "embedded" {
+		t.Errorf("config: got %v", v)
+	}
+}
+
+func TestEmbeddedClientErrors(t *testing.T) {
+	c := newEmbeddedClient(t)
+	_, err := c.ObjectPutRaw(context.Background(), bytes.NewReader(nil), "xml")
+	if e, ok := err.(*Error); !ok || e.Kind != ErrClient || e.Path[0] != "object" {
+		t.Errorf("expected a client error, got %#v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package interplanetary
+
+import (
+	"strings"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// Kinds of daemon failure. Failed commands return an *Error whose Kind is
+// one of these.
+var (
+	// ErrUnknownCommand means the daemon does not know the command.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrNotFound means an object or path named in the request could not be
+	// found.
+	ErrNotFound = errors.New("not found")
+	// ErrClient means the daemon rejected the request, for instance because
+	// of an invalid argument.
+	ErrClient = errors.New("client error")
+	// ErrDaemon means the daemon failed to run the command.
+	ErrDaemon = errors.New("daemon error")
+	// ErrUnreachable means the request could not be sent to the daemon.
+	ErrUnreachable = errors.New("daemon unreachable")
+	// ErrMalformedResponse means the daemon's response could not be
+	// understood.
+	ErrMalformedResponse = errors.New("malformed response")
+)
+
+// Error is the error returned when a daemon command fails. Its Kind is one
+// of the kinds above, or the error of the context, such as
+// context.DeadlineExceeded, when the context ended the command.
+type Error struct {
+	Kind    error    // kind of failure
+	Path    []string // path of the command, such as []string{"object", "get"}
+	Message string   // description of the failure, if any
+	Err     error    // underlying error, if any
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if msg == "" {
+		msg = e.Kind.Error()
+	}
+	return strings.Join(e.Path, " ") + ": " + msg
+}
+
+// Is reports whether target is the kind of e, so that errors.Is can match
+// an *Error against the sentinels.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// notFoundMessages are the messages of the errors commands fail with when an
+// object or a path cannot be resolved.
+var notFoundMessages = []string{
+	"no link named",        // path.Resolver
+	"key not found",        // datastore and blockservice
+	"merkledag: not found", // merkledag
+	"routing: not found",   // routing
+}
+
+// errorKind returns the kind of an error a command reported with code and
+// msg.
+func errorKind(code cmds.ErrorType, msg string) error {
+	for _, m := range notFoundMessages {
+		if strings.Contains(msg, m) {
+			return ErrNotFound
+		}
+	}
+	if code == cmds.ErrClient {
+		return ErrClient
+	}
+	return ErrDaemon
+}
+
+// errMalformed returns an ErrMalformedResponse error for the command of req.
+func errMalformed(req cmds.Request, msg string) error {
+	return &Error{Kind: ErrMalformedResponse, Path: req.Path(), Message: msg}
+}
+
+// errDone returns the error of the command of req, ended by ctx.
+func errDone(req cmds.Request, ctx context.Context) error {
+	return &Error{Kind: ctx.Err(), Path: req.Path(), Err: ctx.Err()}
+}
+
+// wrapMalformed returns an ErrMalformedResponse error for the command of req,
+// caused by err.
+func wrapMalformed(req cmds.Request, err error) error {
+	return &Error{Kind: ErrMalformedResponse, Path: req.Path(), Err: err}
+}
@@ -0,0 +1,61 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		kind   error
+	}{
+		{http.StatusNotFound, "404 page not found", ipfs.ErrUnknownCommand},
+		{http.StatusBadRequest, `{"Message":"invalid key","Code":1}`, ipfs.ErrClient},
+		{http.StatusBadRequest, "Invalid argument", ipfs.ErrClient},
+		{http.StatusInternalServerError, `{"Message":"blockservice: key not found","Code":0}`, ipfs.ErrNotFound},
+		{http.StatusInternalServerError, `{"Message":"no link named \"x\" under Qm","Code":0}`, ipfs.ErrNotFound},
+		{http.StatusInternalServerError, `{"Message":"out of disk space","Code":0}`, ipfs.ErrDaemon},
+		{http.StatusOK, `{"Objects":`, ipfs.ErrMalformedResponse},
+		{http.StatusOK, `{"Objects":[]}`, ipfs.ErrMalformedResponse},
+		{http.StatusOK, `{"Objects":[{"Hash":"x","Links":[{"Name":"a","Hash":"notakey"}]}]}`, ipfs.ErrMalformedResponse},
+	}
+	for _, tc := range cases {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		}))
+		c := newClient(t, serverAddr(t, s))
+		_, err := c.Ls(context.Background(), "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK")
+		s.Close()
+
+		e, ok := err.(*ipfs.Error)
+		if !ok {
+			t.Errorf("%d %s: expected an *Error, got %#v", tc.status, tc.body, err)
+			continue
+		}
+		if e.Kind != tc.kind {
+			t.Errorf("%d %s: got kind %q, want %q", tc.status, tc.body, e.Kind, tc.kind)
+		}
+		if len(e.Path) != 1 || e.Path[0] != "ls" {
+			t.Errorf("%d %s: got path %v", tc.status, tc.body, e.Path)
+		}
+	}
+}
+
+func TestErrorUnreachable(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	addr := serverAddr(t, s)
+	s.Close()
+
+	c := newClient(t, addr)
+	_, err := c.ID(context.Background())
+	if e, ok := err.(*ipfs.Error); !ok || e.Kind != ipfs.ErrUnreachable {
+		t.Errorf("expected ErrUnreachable, got %#v", err)
+	}
+}
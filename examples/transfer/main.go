@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 
 	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
 )
 
 const (
@@ -20,6 +21,7 @@ func main() {
 }
 
 func transfer() error {
+	ctx := context.Background()
 	node1, err := ipfs.NewClient(daemonHostAddr1)
 	if err != nil {
 		return err
@@ -32,16 +34,17 @@ func transfer() error {
 	if err != nil {
 		return err
 	}
-	k, err := node1.Add(bytes.NewReader(data))
+	k, err := node1.Add(ctx, bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
 	fmt.Println("added: " + k.String())
 
-	r, err := node2.Cat(k)
+	r, err := node2.Cat(ctx, k)
 	if err != nil {
 		return err
 	}
+	defer r.Close()
 
 	fmt.Println(r)
 	return nil
@@ -0,0 +1,387 @@
+package interplanetary
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	gopath "path"
+	"strings"
+	"sync"
+	"time"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	dag "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/merkledag"
+	ft "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/unixfs"
+	ftpb "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/unixfs/pb"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+var (
+	errIsDir    = errors.New("is a directory")
+	errNotDir   = errors.New("not a directory")
+	errNegative = errors.New("negative position")
+	errWhence   = errors.New("invalid whence")
+)
+
+// Open opens the unixfs file or directory at name, which takes the form
+// "/<key>/sub/path". It allows the client to be used as an http.FileSystem.
+// http.FileSystem has no way to pass a context, so requests made through the
+// file are never cancelled; use FS to bound them.
+func (c *client) Open(name string) (http.File, error) {
+	return c.FS(context.Background(), 0).Open(name)
+}
+
+// FS returns an http.FileSystem like the client itself, whose files make
+// their requests with ctx. If timeout is positive, the requests of a file
+// are also cancelled once one of its calls, such as a Read, has waited on
+// the daemon for longer than timeout, so that a stalled daemon cannot hold a
+// file open forever. Only the time spent waiting within a call counts: a
+// large file streamed steadily, or read slowly, is not cut short.
+func (c *client) FS(ctx context.Context, timeout time.Duration) http.FileSystem {
+	return &fileSystem{client: c, ctx: ctx, timeout: timeout}
+}
+
+type fileSystem struct {
+	client  *client
+	ctx     context.Context
+	timeout time.Duration
+}
+
+func (fs *fileSystem) Open(name string) (http.File, error) {
+	p := gopath.Clean("/" + name)[1:]
+	if p == "" {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	ctx := newStallContext(fs.ctx, fs.timeout)
+	stop := ctx.watch()
+	fi, err := fs.client.stat(ctx, p)
+	stop()
+	if err != nil {
+		ctx.cancel()
+		// let http.FileServer answer 404 for missing paths
+		if e, ok := err.(*Error); ok && e.Kind == ErrNotFound {
+			err = os.ErrNotExist
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &file{ctx: ctx, client: fs.client, path: p, info: fi}, nil
+}
+
+// stallContext is the context of a file's requests. Besides being done with
+// its parent, it is done, with context.DeadlineExceeded, once a call
+// watching it has run for longer than timeout.
+type stallContext struct {
+	context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+
+	mu      sync.Mutex
+	stalled bool
+}
+
+func newStallContext(parent context.Context, timeout time.Duration) *stallContext {
+	ctx, cancel := context.WithCancel(parent)
+	return &stallContext{Context: ctx, cancel: cancel, timeout: timeout}
+}
+
+// watch starts timing a call. The returned function stops the timer, and
+// must be called when the call returns.
+func (c *stallContext) watch() (stop func()) {
+	if c.timeout <= 0 {
+		return func() {}
+	}
+	t := time.AfterFunc(c.timeout, func() {
+		c.mu.Lock()
+		c.stalled = true
+		c.mu.Unlock()
+		c.cancel()
+	})
+	return func() { t.Stop() }
+}
+
+func (c *stallContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stalled {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+// stat resolves the object at path p and decodes its unixfs metadata.
+func (c *client) stat(ctx context.Context, p string) (*fileInfo, error) {
+	req, err := c.request([]string{"object", "data"}, nil, p)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := res.Reader()
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := r.(io.Closer); ok {
+		defer rc.Close()
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	pbdata, err := ft.FromBytes(data)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+
+	fi := &fileInfo{name: gopath.Base(p)}
+	switch pbdata.GetType() {
+	case ftpb.Data_Directory:
+		fi.dir = true
+	case ftpb.Data_File:
+		fi.size = int64(pbdata.GetFilesize())
+	case ftpb.Data_Raw:
+		fi.size = int64(len(pbdata.GetData()))
+	default:
+		return nil, wrapMalformed(req, ft.ErrUnrecognizedType)
+	}
+	return fi, nil
+}
+
+// file implements http.File on top of the daemon's cat and ls commands.
+type file struct {
+	ctx    *stallContext
+	client *client
+	path   string
+	info   *fileInfo
+
+	// key is the key of the file at path, looked for once resolved is set.
+	// It stays nil if the key could not be resolved.
+	key      Key
+	resolved bool
+
+	// offset is the position of the next Read. r, when set, is a cat stream
+	// positioned at offset.
+	offset int64
+	r      io.Reader
+
+	// entries holds the directory links not yet returned by Readdir.
+	entries []Link
+	listed  bool
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.info.dir {
+		return 0, errIsDir
+	}
+	defer f.ctx.watch()()
+	if f.r == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.r.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+// open starts a cat stream at the current offset. Past the start of the
+// file, the stream only fetches the blocks from the offset on, once the key
+// of the file is resolved; failing that, the file is read from its start and
+// skipped up to the offset.
+func (f *file) open() error {
+	if f.offset > 0 && !f.resolved {
+		f.key, _ = f.resolve()
+		f.resolved = true
+	}
+	if f.offset > 0 && f.key != nil {
+		r, err := f.client.CatRange(f.ctx, f.key, f.offset, -1)
+		if err != nil {
+			return err
+		}
+		f.r = r
+		return nil
+	}
+
+	req, err := f.client.request([]string{"cat"}, nil, f.path)
+	if err != nil {
+		return err
+	}
+	res, err := f.client.send(f.ctx, req)
+	if err != nil {
+		return err
+	}
+	r, err := res.Reader()
+	if err != nil {
+		return err
+	}
+	f.r = r
+	if _, err := io.CopyN(ioutil.Discard, r, f.offset); err != nil && err != io.EOF {
+		f.release()
+		return err
+	}
+	return nil
+}
+
+// resolve returns the key of the file, following the links named by its
+// path from the key the path starts with.
+func (f *file) resolve() (Key, error) {
+	names := strings.Split(f.path, "/")
+	k, err := ParseKey(names[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names[1:] {
+		links, err := f.client.ObjectLinks(f.ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		k = nil
+		for _, l := range links {
+			if l.Name == name {
+				k = l.Hash
+				break
+			}
+		}
+		if k == nil {
+			return nil, os.ErrNotExist
+		}
+	}
+	return k, nil
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.info.dir {
+		return 0, errIsDir
+	}
+	switch whence {
+	case os.SEEK_SET:
+	case os.SEEK_CUR:
+		offset += f.offset
+	case os.SEEK_END:
+		offset += f.info.size
+	default:
+		return 0, errWhence
+	}
+	if offset < 0 {
+		return 0, errNegative
+	}
+	if offset != f.offset {
+		// the stream is reopened at the new offset by the next Read
+		f.release()
+		f.offset = offset
+	}
+	return offset, nil
+}
+
+// lsBatch is the most keys listed by one ls request, which takes them as
+// query arguments.
+const lsBatch = 64
+
+// Readdir lists the directory. The daemon does not list the content size of
+// files, only that of their objects, so the FileInfo returned report a size
+// of 0; open a file and Stat it for its size.
+func (f *file) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.info.dir {
+		return nil, errNotDir
+	}
+	defer f.ctx.watch()()
+	if !f.listed {
+		objects, err := f.client.Ls(f.ctx, f.path)
+		if err != nil {
+			return nil, err
+		}
+		f.entries = objects[0].Links
+		f.listed = true
+	}
+
+	n := len(f.entries)
+	if count > 0 && count < n {
+		n = count
+	}
+	if count > 0 && n == 0 {
+		return nil, io.EOF
+	}
+
+	// list the entries in batches, rather than stat each of them
+	entries := f.entries[:n]
+	infos := make([]os.FileInfo, 0, len(entries))
+	for len(entries) > 0 {
+		batch := entries
+		if len(batch) > lsBatch {
+			batch = batch[:lsBatch]
+		}
+		args := make([]string, len(batch))
+		for i, l := range batch {
+			args[i] = l.Hash.String()
+		}
+		objects, err := f.client.Ls(f.ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		for i, l := range batch {
+			infos = append(infos, linkInfo(l, objects[i].Links))
+		}
+		entries = entries[len(batch):]
+	}
+	f.entries = f.entries[n:]
+	return infos, nil
+}
+
+// emptyDirSize is the size of the object of an empty directory.
+var emptyDirSize = func() uint64 {
+	size, err := (&dag.Node{Data: ft.FolderPBData()}).Size()
+	if err != nil {
+		panic(err)
+	}
+	return size
+}()
+
+// linkInfo describes the object linked by l, given its own links. A
+// directory names its links, while the links of a file to its blocks are
+// unnamed; an empty directory, which has none, is told by its size.
+func linkInfo(l Link, links []Link) *fileInfo {
+	dir := len(links) > 0 && links[0].Name != "" || len(links) == 0 && l.Size == emptyDirSize
+	return &fileInfo{name: l.Name, dir: dir}
+}
+
+func (f *file) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *file) Close() error {
+	f.release()
+	f.ctx.cancel()
+	return nil
+}
+
+// release closes the current cat stream, if any.
+func (f *file) release() {
+	if c, ok := f.r.(io.Closer); ok {
+		c.Close()
+	}
+	f.r = nil
+}
+
+// fileInfo describes a unixfs file or directory.
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi *fileInfo) Name() string { return fi.name }
+func (fi *fileInfo) Size() int64  { return fi.size }
+
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ModTime returns the zero time; IPFS objects carry no modification time.
+func (fi *fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *fileInfo) IsDir() bool        { return fi.dir }
+func (fi *fileInfo) Sys() interface{}   { return nil }
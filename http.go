@@ -0,0 +1,232 @@
+package interplanetary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	cmds_http "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands/http"
+)
+
+// httpSender sends requests to a daemon over its HTTP API.
+type httpSender struct {
+	host       string
+	scheme     string
+	apiPath    string
+	header     http.Header
+	userAgent  string
+	httpClient *http.Client
+}
+
+// streamHeader is set by the daemon on responses whose output is a raw
+// stream rather than a marshalled value.
+const streamHeader = "X-Stream-Output"
+
+// send sends req to the daemon and decodes the response. Streamed output is
+// set as an io.ReadCloser, which is closed when ctx is done. Failures are
+// returned as an *Error.
+func (c *httpSender) send(ctx context.Context, req cmds.Request) (cmds.Response, error) {
+	httpRes, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res := cmds.NewResponse(req)
+	if httpRes.Header.Get(streamHeader) != "" {
+		res.SetOutput(newCtxBody(ctx, req, httpRes.Body))
+		return res, nil
+	}
+
+	defer httpRes.Body.Close()
+	out, err := decodeOutput(httpRes.Body, req.Command().Type)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	res.SetOutput(out)
+	return res, nil
+}
+
+// readCloser returns the streamed output of res.
+func readCloser(res cmds.Response) (io.ReadCloser, error) {
+	r, err := res.Reader()
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc, nil
+	}
+	return ioutil.NopCloser(r), nil
+}
+
+// stream sends req to the daemon and returns the undecoded response body,
+// so that large outputs can be consumed as they arrive. The body is closed
+// when ctx is done.
+func (c *httpSender) stream(ctx context.Context, req cmds.Request) (io.ReadCloser, error) {
+	httpRes, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return newCtxBody(ctx, req, httpRes.Body), nil
+}
+
+// do performs the HTTP request for req. The request is aborted when ctx is
+// done. Error responses are decoded and returned as an *Error.
+func (c *httpSender) do(ctx context.Context, req cmds.Request) (*http.Response, error) {
+	// always request JSON, which is what the output is decoded from
+	req.SetOption(cmds.EncShort, cmds.JSON)
+
+	u := url.URL{
+		Scheme:   c.scheme,
+		Host:     c.host,
+		Path:     c.apiPath + "/" + strings.Join(req.Path(), "/"),
+		RawQuery: encodeQuery(req),
+	}
+
+	var body io.Reader
+	var fileReader *cmds_http.MultiFileReader
+	if req.Files() != nil {
+		fileReader = cmds_http.NewMultiFileReader(req.Files(), true)
+		body = fileReader
+	}
+	httpReq, err := http.NewRequest("POST", u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	if fileReader != nil {
+		httpReq.Header.Set("Content-Type", "multipart/form-data; boundary="+fileReader.Boundary())
+		httpReq.Header.Set("Content-Disposition", "form-data: name=\"files\"")
+	} else {
+		httpReq.Header.Set("Content-Type", "application/octet-stream")
+	}
+	for k, vs := range c.header {
+		for _, v := range vs {
+			httpReq.Header.Add(k, v)
+		}
+	}
+	httpReq.Header.Set("User-Agent", c.userAgent)
+
+	httpRes, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, errDone(req, ctx)
+		}
+		return nil, &Error{Kind: ErrUnreachable, Path: req.Path(), Err: err}
+	}
+	if httpRes.StatusCode >= http.StatusBadRequest {
+		defer httpRes.Body.Close()
+		return nil, decodeError(req.Path(), httpRes)
+	}
+	return httpRes, nil
+}
+
+// encodeQuery encodes the options and string arguments of req as URL query
+// parameters.
+func encodeQuery(req cmds.Request) string {
+	query := url.Values{}
+	for k, v := range req.Options() {
+		query.Set(k, fmt.Sprintf("%v", v))
+	}
+	for _, arg := range req.Arguments() {
+		query.Add("arg", arg)
+	}
+	return query.Encode()
+}
+
+// decodeOutput decodes a marshalled command output from r. The output is
+// decoded into a new value of the type of typ, which is the Type of the
+// command, so that the command definition itself is never written to.
+func decodeOutput(r io.Reader, typ interface{}) (interface{}, error) {
+	t := reflect.TypeOf(typ)
+	if t == nil || t.Kind() != reflect.Ptr {
+		var v interface{}
+		if err := json.NewDecoder(r).Decode(&v); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return v, nil
+	}
+	v := reflect.New(t.Elem()).Interface()
+	if err := json.NewDecoder(r).Decode(v); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeError decodes the error carried by a failed response to the command
+// at path. Errors reported by the command itself are classified by their
+// cmds.ErrorType, others by the HTTP status.
+func decodeError(path []string, httpRes *http.Response) error {
+	e := &Error{Path: path}
+	switch {
+	case httpRes.StatusCode == http.StatusNotFound:
+		e.Kind = ErrUnknownCommand
+	case httpRes.StatusCode < http.StatusInternalServerError:
+		e.Kind = ErrClient
+	default:
+		e.Kind = ErrDaemon
+	}
+	body, err := ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		e.Err = err
+		return e
+	}
+
+	var cmdErr cmds.Error
+	if e.Kind != ErrUnknownCommand && json.Unmarshal(body, &cmdErr) == nil && cmdErr.Message != "" {
+		e.Kind = errorKind(cmdErr.Code, cmdErr.Message)
+		e.Message = cmdErr.Message
+		return e
+	}
+	e.Message = strings.TrimSpace(string(body))
+	return e
+}
+
+// ctxBody is a response body that is closed when its context is done, or
+// once it has been read to the end. Reads cut short by the context return
+// its error, as an *Error naming the command of req.
+type ctxBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	req  cmds.Request
+	once sync.Once
+	done chan struct{}
+}
+
+func newCtxBody(ctx context.Context, req cmds.Request, body io.ReadCloser) *ctxBody {
+	b := &ctxBody{ReadCloser: body, ctx: ctx, req: req, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.Close()
+		case <-b.done:
+		}
+	}()
+	return b
+}
+
+func (b *ctxBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.Close()
+	}
+	if err != nil && err != io.EOF && b.ctx.Err() != nil {
+		err = errDone(b.req, b.ctx)
+	}
+	return n, err
+}
+
+func (b *ctxBody) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.done)
+		err = b.ReadCloser.Close()
+	})
+	return err
+}
@@ -0,0 +1,71 @@
+package interplanetary
+
+import (
+	"encoding/base64"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+	crypto "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/crypto"
+	peer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/peer"
+	ma "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multiaddr"
+)
+
+// PeerInfo describes the identity of an IPFS node and the addresses it
+// advertises.
+type PeerInfo struct {
+	ID              peer.ID
+	PublicKey       crypto.PubKey
+	Addresses       []ma.Multiaddr
+	AgentVersion    string
+	ProtocolVersion string
+}
+
+// ID returns the identity of the node the client is talking to.
+func (c *client) ID(ctx context.Context) (*PeerInfo, error) {
+	return c.peerInfo(ctx)
+}
+
+// PeerInfo looks up the identity of the peer with the given ID.
+func (c *client) PeerInfo(ctx context.Context, id peer.ID) (*PeerInfo, error) {
+	return c.peerInfo(ctx, id.Pretty())
+}
+
+func (c *client) peerInfo(ctx context.Context, args ...string) (*PeerInfo, error) {
+	req, err := c.request([]string{"id"}, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.IdOutput)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+
+	info := &PeerInfo{
+		ID:              peer.DecodePrettyID(out.ID),
+		Addresses:       make([]ma.Multiaddr, len(out.Addresses)),
+		AgentVersion:    out.AgentVersion,
+		ProtocolVersion: out.ProtocolVersion,
+	}
+	if len(info.ID) == 0 {
+		return nil, errMalformed(res.Request(), "malformed response")
+	}
+	pkb, err := base64.StdEncoding.DecodeString(out.PublicKey)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	info.PublicKey, err = crypto.UnmarshalPublicKey(pkb)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	for i, a := range out.Addresses {
+		info.Addresses[i], err = ma.NewMultiaddr(a)
+		if err != nil {
+			return nil, wrapMalformed(req, err)
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,67 @@
+package interplanetary_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	crypto "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/crypto"
+	peer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/peer"
+)
+
+// stubID returns a new peer ID and the output of the id command describing
+// it, with the public key pkb.
+func stubID(t *testing.T) (id peer.ID, pkb []byte, out string) {
+	_, pk, err := crypto.GenerateKeyPair(crypto.RSA, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkb, err = pk.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := pk.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = `{"ID":"` + peer.ID(h).Pretty() + `","PublicKey":"` + base64.StdEncoding.EncodeToString(pkb) + `",
+		"Addresses":["/ip4/127.0.0.1/tcp/4001"],"AgentVersion":"go-ipfs/0.1.7","ProtocolVersion":"ipfs/0.1.0"}`
+	return peer.ID(h), pkb, out
+}
+
+func TestPeerInfo(t *testing.T) {
+	id, pkb, out := stubID(t)
+	for _, args := range [][]string{nil, {id.Pretty()}} {
+		c := stubClient(t, stubCommand(t, "id", args, out))
+		get := c.ID
+		if args != nil {
+			get = func(ctx context.Context) (*ipfs.PeerInfo, error) { return c.PeerInfo(ctx, id) }
+		}
+		info, err := get(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := info.PublicKey.Bytes()
+		if err != nil || !bytes.Equal(b, pkb) {
+			t.Errorf("%v: public key does not match", args)
+		}
+		if !info.ID.Equal(id) || len(info.Addresses) != 1 || info.Addresses[0].String() != "/ip4/127.0.0.1/tcp/4001" ||
+			info.AgentVersion != "go-ipfs/0.1.7" || info.ProtocolVersion != "ipfs/0.1.0" {
+			t.Errorf("%v: got %+v", args, info)
+		}
+	}
+}
+
+func TestPeerInfoMalformed(t *testing.T) {
+	for _, out := range []string{
+		`{"ID":""}`,
+		`{"ID":"QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ","PublicKey":"!"}`,
+	} {
+		c := stubClient(t, stubCommand(t, "id", nil, out))
+		if _, err := c.ID(context.Background()); err == nil {
+			t.Errorf("%s: expected an error", out)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package interplanetary
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	chunk "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/importer/chunk"
+	dag "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/merkledag"
+	u "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+	mh "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multihash"
+)
+
+// importFiles adds f by building its DAG in the client, splitting content
+// with spl. Each node is pushed to the daemon as a block as soon as it is
+// built, and the result is pinned recursively, as the daemon's add command
+// would pin it.
+func (c *client) importFiles(ctx context.Context, f cmds.File, spl chunk.BlockSplitter) ([]addedFile, error) {
+	var files []addedFile
+	if _, err := fileNode(f, filePath("", f), spl, &remoteDAG{ctx, c}, &files); err != nil {
+		return nil, err
+	}
+	if err := c.Pin(ctx, files[len(files)-1].key, true); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// remoteDAG is a dag.DAGService backed by the daemon: nodes are stored with
+// block put and fetched with block get.
+type remoteDAG struct {
+	ctx context.Context
+	c   *client
+}
+
+func (d *remoteDAG) Add(n *dag.Node) (u.Key, error) {
+	data, err := n.Encoded(false)
+	if err != nil {
+		return "", err
+	}
+	want, err := n.Key()
+	if err != nil {
+		return "", err
+	}
+	s, err := d.c.blockPut(d.ctx, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	if k := u.Key(s.Key.Multihash()); k != want {
+		return "", &Error{
+			Kind:    ErrMalformedResponse,
+			Path:    []string{"block", "put"},
+			Message: "block of " + want.Pretty() + " stored as " + k.Pretty(),
+		}
+	}
+	return want, nil
+}
+
+func (d *remoteDAG) AddRecursive(n *dag.Node) error {
+	if _, err := d.Add(n); err != nil {
+		return err
+	}
+	for _, l := range n.Links {
+		if l.Node != nil {
+			if err := d.AddRecursive(l.Node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *remoteDAG) Get(k u.Key) (*dag.Node, error) {
+	key, err := KeyFromMultihash(mh.Multihash(k))
+	if err != nil {
+		return nil, err
+	}
+	r, err := d.c.BlockGet(d.ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := dag.Decoded(data)
+	if err != nil {
+		return nil, errMalformedBlock(err)
+	}
+	return n, nil
+}
+
+func (d *remoteDAG) Remove(*dag.Node) error {
+	return errors.New("removing nodes is not supported")
+}
@@ -0,0 +1,155 @@
+package interplanetarytest
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+
+	b58 "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-base58"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	cmds_http "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands/http"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+	core "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+	ci "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/crypto"
+)
+
+// Daemon is a stand-in for an IPFS daemon: it serves the daemon's HTTP API,
+// backed by a mock node, on a local test server.
+type Daemon struct {
+	Server *httptest.Server
+
+	// Node is the mock node the daemon runs commands on.
+	Node *core.IpfsNode
+
+	// Addr is the multiaddr of the API, to pass to interplanetary.NewClient.
+	Addr string
+
+	// ConfigRoot is the directory holding the daemon's config file.
+	ConfigRoot string
+}
+
+// NewDaemon starts a stand-in daemon with an empty node. It must be closed
+// once done with.
+func NewDaemon() (*Daemon, error) {
+	n, err := NewMockNode()
+	if err != nil {
+		return nil, err
+	}
+	return serve(n, mockConfig(n))
+}
+
+// NewOnlineDaemon starts a stand-in daemon whose node is online, with an
+// in-memory datastore and a swarm listening on a local port, so that the
+// commands needing a network, such as swarm, id and name publish, can run.
+// The node bootstraps from the nodes of peers. It must be closed once done
+// with.
+func NewOnlineDaemon(peers ...*Daemon) (*Daemon, error) {
+	sk, pk, err := ci.GenerateKeyPair(ci.RSA, 1024)
+	if err != nil {
+		return nil, err
+	}
+	skb, err := sk.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	id, err := pk.Hash()
+	if err != nil {
+		return nil, err
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config.Config{
+		Identity: config.Identity{
+			PeerID:  b58.Encode(id),
+			PrivKey: base64.StdEncoding.EncodeToString(skb),
+		},
+		Datastore: config.Datastore{Type: "memory"},
+		Addresses: config.Addresses{Swarm: []string{"/ip4/127.0.0.1/tcp/" + port}},
+		Version:   config.VersionDefaultValue(),
+	}
+	for _, p := range peers {
+		cfg.Bootstrap = append(cfg.Bootstrap, &config.BootstrapPeer{
+			Address: p.Node.Config.Addresses.Swarm[0],
+			PeerID:  p.Node.Identity.ID().Pretty(),
+		})
+	}
+	n, err := core.NewIpfsNode(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	d, err := serve(n, cfg)
+	if err != nil {
+		n.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// freePort returns a local TCP port no one listens on. The swarm of a node
+// does not report the port it was given when asked for port 0.
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	return port, err
+}
+
+// serve starts a daemon running commands on n, configured by cfg. The
+// address of the API is added to cfg, which is then written to the daemon's
+// config file.
+func serve(n *core.IpfsNode, cfg *config.Config) (*Daemon, error) {
+	root, err := ioutil.TempDir("", "interplanetarytest")
+	if err != nil {
+		return nil, err
+	}
+	filename, err := config.Filename(root)
+	if err != nil {
+		os.RemoveAll(root)
+		return nil, err
+	}
+
+	d := &Daemon{Node: n, ConfigRoot: root}
+	ctx := cmds.Context{
+		ConfigRoot: root,
+		LoadConfig: func(string) (*config.Config, error) {
+			return config.Load(filename)
+		},
+		ConstructNode: func() (*core.IpfsNode, error) {
+			return n, nil
+		},
+	}
+	d.Server = httptest.NewServer(cmds_http.NewHandler(ctx, core_cmds.Root, ""))
+	host, port, err := net.SplitHostPort(d.Server.Listener.Addr().String())
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+	d.Addr = "/ip4/" + host + "/tcp/" + port
+
+	cfg.Addresses.API = d.Addr
+	n.Config = cfg
+	if err := config.WriteConfigFile(filename, cfg); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Close stops the daemon, and its node if online, and removes its config.
+func (d *Daemon) Close() {
+	d.Server.Close()
+	if d.Node.ContextCloser != nil {
+		// nodes made by core.NewMockNode have nothing to stop
+		d.Node.Close()
+	}
+	os.RemoveAll(d.ConfigRoot)
+}
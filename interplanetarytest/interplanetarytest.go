@@ -0,0 +1,122 @@
+// Package interplanetarytest provides a fake interplanetary.Client for tests
+// that need an IPFS node but not a daemon.
+package interplanetarytest
+
+import (
+	"io/ioutil"
+	"os"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	bserv "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/blockservice"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+	core "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core"
+	mdag "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/merkledag"
+	nsys "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/namesys"
+	path "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/path"
+	pin "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/pin"
+	u "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util"
+)
+
+// Client is a fake ipfs.Client backed by a node made by core.NewMockNode:
+// storage is an in-memory map, IPNS records go to a mock router and there is
+// no network. Commands run as they would on a daemon, except the ones that
+// need a network, such as swarm and diag.
+type Client struct {
+	ipfs.Client
+
+	// Node is the mock node the client runs commands on.
+	Node *core.IpfsNode
+
+	// ConfigRoot is the temporary directory holding the node's config file,
+	// which the config and bootstrap commands read and write.
+	ConfigRoot string
+}
+
+var _ ipfs.Client = (*Client)(nil)
+
+// NewClient returns a fake client with an empty node. It must be closed
+// once done with, to remove its config.
+func NewClient() (*Client, error) {
+	n, err := NewMockNode()
+	if err != nil {
+		return nil, err
+	}
+	root, err := ioutil.TempDir("", "interplanetarytest")
+	if err != nil {
+		return nil, err
+	}
+	filename, err := config.Filename(root)
+	if err == nil {
+		n.Config = mockConfig(n)
+		err = config.WriteConfigFile(filename, n.Config)
+	}
+	if err != nil {
+		os.RemoveAll(root)
+		return nil, err
+	}
+	return &Client{Client: ipfs.NewNodeClient(n, root).Client, Node: n, ConfigRoot: root}, nil
+}
+
+// Close removes the client's config.
+func (c *Client) Close() error {
+	return os.RemoveAll(c.ConfigRoot)
+}
+
+// mockConfig returns the config of n, a node made by NewMockNode.
+func mockConfig(n *core.IpfsNode) *config.Config {
+	return &config.Config{
+		Identity:  config.Identity{PeerID: n.Identity.ID().Pretty()},
+		Datastore: config.Datastore{Type: "memory"},
+		Version:   config.VersionDefaultValue(),
+	}
+}
+
+// NewMockNode returns a node made by core.NewMockNode, completed with the
+// block service and pinner the add, block and pin commands need.
+func NewMockNode() (*core.IpfsNode, error) {
+	n, err := core.NewMockNode()
+	if err != nil {
+		return nil, err
+	}
+	n.Blocks, err = bserv.NewBlockService(n.Datastore, nil)
+	if err != nil {
+		return nil, err
+	}
+	n.DAG = mdag.NewDAGService(n.Blocks)
+	n.Resolver = &path.Resolver{DAG: n.DAG}
+	n.Pinning = pin.NewPinner(n.Datastore, n.DAG)
+	return n, nil
+}
+
+// Publish publishes k at the node's own IPNS name, like the daemon's name
+// publish command. The daemon's command refuses to run offline, so the
+// record is published to the mock router directly.
+func (c *Client) Publish(ctx context.Context, name string, k ipfs.Key) (*ipfs.IpnsEntry, error) {
+	p := []string{"name", "publish"}
+	if name != "" && name != c.Node.Identity.ID().Pretty() {
+		return nil, &ipfs.Error{Kind: ipfs.ErrDaemon, Path: p, Message: "keychains not yet implemented"}
+	}
+	sk := c.Node.Identity.PrivKey()
+	if err := nsys.NewRoutingPublisher(c.Node.Routing).Publish(sk, k.String()); err != nil {
+		return nil, &ipfs.Error{Kind: ipfs.ErrDaemon, Path: p, Err: err}
+	}
+	hash, err := sk.GetPublic().Hash()
+	if err != nil {
+		return nil, &ipfs.Error{Kind: ipfs.ErrDaemon, Path: p, Err: err}
+	}
+	return &ipfs.IpnsEntry{Name: u.Key(hash).String(), Value: k.String()}, nil
+}
+
+// Resolve gets the value published at the IPNS name, like the daemon's name
+// resolve command. If name is empty, the node's own peer ID is used.
+func (c *Client) Resolve(ctx context.Context, name string) (*ipfs.IpnsEntry, error) {
+	if name == "" {
+		name = c.Node.Identity.ID().String()
+	}
+	value, err := c.Node.Namesys.Resolve(name)
+	if err != nil {
+		return nil, &ipfs.Error{Kind: ipfs.ErrDaemon, Path: []string{"name", "resolve"}, Err: err}
+	}
+	return &ipfs.IpnsEntry{Name: name, Value: value}, nil
+}
@@ -0,0 +1,182 @@
+package interplanetarytest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestClient(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	data := []byte("hello, fake")
+	k, err := c.Add(ctx, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Cat(ctx, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("cat: got %q", got)
+	}
+
+	objects, err := c.Ls(ctx, k.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Hash != k.String() {
+		t.Errorf("ls: got %+v", objects)
+	}
+
+	pins, err := c.Pins(ctx, ipfs.RecursivePins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || !pins[0].Equal(k) {
+		t.Errorf("added key is not pinned: %v", pins)
+	}
+	if err := c.Unpin(ctx, k, true); err != nil {
+		t.Fatal(err)
+	}
+	pins, err = c.Pins(ctx, ipfs.RecursivePins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 0 {
+		t.Errorf("unpinned key is still pinned: %v", pins)
+	}
+}
+
+func TestClientIPNS(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	k, err := c.Add(ctx, bytes.NewReader([]byte("published")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := c.Publish(ctx, "", k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Value != k.String() {
+		t.Errorf("publish: got %+v", entry)
+	}
+
+	resolved, err := c.Resolve(ctx, entry.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Value != k.String() {
+		t.Errorf("resolve: got %+v", resolved)
+	}
+	self, err := c.Resolve(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if self.Name != entry.Name || self.Value != k.String() {
+		t.Errorf("resolve self: got %+v, want %+v", self, entry)
+	}
+
+	if _, err := c.Publish(ctx, entry.Name, k); err != nil {
+		t.Errorf("publish at the node's own name: %v", err)
+	}
+	if _, err := c.Publish(ctx, "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ", k); err == nil {
+		t.Error("expected an error publishing to another name")
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if err := c.SetConfig(ctx, "Tour.Last", "config"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Config(ctx, "Tour.Last")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "config" {
+		t.Errorf("config: got %v", v)
+	}
+
+	p, err := ipfs.ParsePeerAddr("/ip4/104.131.131.82/tcp/4001/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.BootstrapAdd(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	peers, err := c.Bootstrap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].String() != p.String() {
+		t.Errorf("bootstrap: got %v", peers)
+	}
+}
+
+func TestOnlineDaemon(t *testing.T) {
+	a, err := NewOnlineDaemon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := NewOnlineDaemon(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	ctx := context.Background()
+
+	c, err := ipfs.NewClient(b.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.ID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !id.ID.Equal(b.Node.Identity.ID()) {
+		t.Errorf("id: got %s, want %s", id.ID.Pretty(), b.Node.Identity.ID().Pretty())
+	}
+
+	// b bootstraps from a in the background
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		peers, err := c.SwarmPeers(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(peers) == 1 && peers[0].ID.Equal(a.Node.Identity.ID()) {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("not connected to the bootstrap peer: %v", peers)
+		}
+	}
+}
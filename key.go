@@ -1,26 +1,111 @@
 package interplanetary
 
 import (
+	"bytes"
+	"encoding/json"
+
 	mh "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multihash"
 )
 
+// Key names an IPFS object by the multihash of its content. Keys encode to
+// text, and to JSON, as their base58 string.
 type Key interface {
+	// String returns the base58 encoding of the key.
 	String() string
+	// Hex returns the hex encoding of the key.
+	Hex() string
+	// Multihash returns the multihash underlying the key.
+	Multihash() mh.Multihash
+	// Decoded returns the hash function, length and digest of the
+	// multihash.
+	Decoded() *mh.DecodedMultihash
+	// Equal reports whether the key is the same as other.
+	Equal(other Key) bool
+
+	MarshalText() ([]byte, error)
+	MarshalJSON() ([]byte, error)
 }
 
-// mhKey is a key backed by a Multihash
-type mhKey struct {
+// MultihashKey is the Key implementation returned by this package. Unlike
+// Key, it can be decoded into, so it is the type to use for keys stored in
+// text or JSON. The zero MultihashKey is not a valid key: it only exists to
+// be decoded into, and its Decoded method returns nil.
+type MultihashKey struct {
 	mh mh.Multihash
 }
 
-func parseKey(maybe string) (Key, error) {
-	h, err := mh.FromB58String(maybe)
+// ParseKey parses the base58 encoding of a key.
+func ParseKey(s string) (Key, error) {
+	h, err := mh.FromB58String(s)
+	if err != nil {
+		return nil, err
+	}
+	return &MultihashKey{mh: h}, nil
+}
+
+// ParseHexKey parses the hex encoding of a key.
+func ParseHexKey(s string) (Key, error) {
+	h, err := mh.FromHexString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &MultihashKey{mh: h}, nil
+}
+
+// KeyFromMultihash returns the key for h. It fails if h is malformed or
+// uses an unknown hash function.
+func KeyFromMultihash(h mh.Multihash) (Key, error) {
+	h, err := mh.Cast(h)
 	if err != nil {
 		return nil, err
 	}
-	return &mhKey{mh: h}, nil
+	return &MultihashKey{mh: h}, nil
 }
 
-func (k *mhKey) String() string {
+func (k MultihashKey) String() string {
 	return k.mh.B58String()
 }
+
+func (k MultihashKey) Hex() string {
+	return k.mh.HexString()
+}
+
+func (k MultihashKey) Multihash() mh.Multihash {
+	return k.mh
+}
+
+func (k MultihashKey) Decoded() *mh.DecodedMultihash {
+	// the multihash was validated when the key was made or decoded, so
+	// only the zero key fails to decode
+	dm, _ := mh.Decode(k.mh)
+	return dm
+}
+
+func (k MultihashKey) Equal(other Key) bool {
+	return other != nil && bytes.Equal(k.mh, other.Multihash())
+}
+
+func (k MultihashKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *MultihashKey) UnmarshalText(text []byte) error {
+	h, err := mh.FromB58String(string(text))
+	if err != nil {
+		return err
+	}
+	k.mh = h
+	return nil
+}
+
+func (k MultihashKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *MultihashKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return k.UnmarshalText([]byte(s))
+}
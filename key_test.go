@@ -1,10 +1,15 @@
 package interplanetary
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+
+	mh "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multihash"
+)
 
 func TestKeyFromString(t *testing.T) {
 	maybe := "Qmf7UC9uXXTxhmYHPJaBsDureMsth3wJzCg4kSTzPV5WBn"
-	k, err := parseKey(maybe)
+	k, err := ParseKey(maybe)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -12,3 +17,64 @@ func TestKeyFromString(t *testing.T) {
 		t.Fatal("transformation changed the key")
 	}
 }
+
+func TestKeyEncodings(t *testing.T) {
+	k, err := ParseKey("Qmf7UC9uXXTxhmYHPJaBsDureMsth3wJzCg4kSTzPV5WBn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm := k.Decoded(); dm.Name != "sha2-256" || len(dm.Digest) != 32 {
+		t.Errorf("unexpected decoded multihash: %+v", dm)
+	}
+
+	h, err := ParseHexKey(k.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Equal(k) {
+		t.Error("hex encoding changed the key")
+	}
+	m, err := KeyFromMultihash(k.Multihash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Equal(k) {
+		t.Error("multihash changed the key")
+	}
+
+	var v struct{ Root MultihashKey }
+	data, err := json.Marshal(struct{ Root Key }{k})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Root.Equal(k) {
+		t.Errorf("JSON encoding changed the key: %s", data)
+	}
+	// a MultihashKey held by value encodes the same as a Key
+	if again, err := json.Marshal(v); err != nil || string(again) != string(data) {
+		t.Errorf("got %s, want %s (%v)", again, data, err)
+	}
+}
+
+func TestKeyInvalid(t *testing.T) {
+	if _, err := ParseKey("not a key"); err == nil {
+		t.Error("expected an error parsing an invalid key")
+	}
+	digest := make([]byte, 32)
+	if _, err := KeyFromMultihash(mh.Multihash(append([]byte{0x99, 32}, digest...))); err == nil {
+		t.Error("expected an error for an unknown hash function")
+	}
+	if _, err := KeyFromMultihash(mh.Multihash(append([]byte{mh.SHA2_256, 31}, digest...))); err == nil {
+		t.Error("expected an error for an inconsistent length")
+	}
+	var k MultihashKey
+	if k.Decoded() != nil {
+		t.Error("expected the zero key not to decode")
+	}
+	if err := json.Unmarshal([]byte(`"Qm"`), &k); err == nil {
+		t.Error("expected an error decoding an invalid key")
+	}
+}
@@ -0,0 +1,65 @@
+package interplanetary
+
+import (
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+)
+
+// Link is a named link from an object to another object.
+type Link struct {
+	Name string
+	Hash Key
+	// Size is the total size of the linked object and its descendants.
+	Size uint64
+}
+
+// Object is a listed object and the links it contains.
+type Object struct {
+	// Hash is the key or path the object was listed by.
+	Hash  string
+	Links []Link
+}
+
+// Ls lists the links of the objects named by keysOrPaths, which may be keys
+// or paths of the form "<key>/sub/path".
+func (c *client) Ls(ctx context.Context, keysOrPaths ...string) ([]Object, error) {
+	req, err := c.request([]string{"ls"}, nil, keysOrPaths...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.LsOutput)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	if len(out.Objects) != len(keysOrPaths) {
+		return nil, errMalformed(res.Request(), "malformed response")
+	}
+
+	objects := make([]Object, len(out.Objects))
+	for i, o := range out.Objects {
+		links, err := convertLinks(req, o.Links)
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = Object{Hash: o.Hash, Links: links}
+	}
+	return objects, nil
+}
+
+// convertLinks converts the links listed in the output of req.
+func convertLinks(req cmds.Request, in []core_cmds.Link) ([]Link, error) {
+	links := make([]Link, len(in))
+	for i, l := range in {
+		k, err := ParseKey(l.Hash)
+		if err != nil {
+			return nil, wrapMalformed(req, err)
+		}
+		links[i] = Link{Name: l.Name, Hash: k, Size: l.Size}
+	}
+	return links, nil
+}
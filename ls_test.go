@@ -0,0 +1,71 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+// stubClient returns a client of a test server answering requests with
+// handler in place of the daemon.
+func stubClient(t *testing.T, handler http.HandlerFunc) ipfs.Client {
+	s := httptest.NewServer(handler)
+	t.Cleanup(s.Close)
+	return newClient(t, serverAddr(t, s))
+}
+
+// stubCommand returns a handler answering the command at path, called with
+// args, with body encoded as JSON. Other requests fail the test.
+func stubCommand(t *testing.T, path string, args []string, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query()["arg"]; r.URL.Path != "/api/v0/"+path || !reflect.DeepEqual(got, args) {
+			t.Errorf("unexpected request %s %v", r.URL.Path, got)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func TestLs(t *testing.T) {
+	ctx := context.Background()
+	const (
+		dir  = "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK"
+		file = "QmWfVY9y3xjsixTgbd9AorQxH7VtMpzfx2HaWtsoUYecaX"
+	)
+	c := stubClient(t, stubCommand(t, "ls", []string{dir, dir + "/sub"}, `{"Objects":[
+		{"Hash":"`+dir+`","Links":[{"Name":"sub","Hash":"`+file+`","Size":12}]},
+		{"Hash":"`+dir+`/sub","Links":[]}
+	]}`))
+
+	objects, err := c.Ls(ctx, dir, dir+"/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 || objects[0].Hash != dir || objects[1].Hash != dir+"/sub" || len(objects[1].Links) != 0 {
+		t.Fatalf("unexpected listing: %+v", objects)
+	}
+	l := objects[0].Links
+	if len(l) != 1 || l[0].Name != "sub" || l[0].Hash.String() != file || l[0].Size != 12 {
+		t.Errorf("unexpected links: %+v", l)
+	}
+}
+
+func TestLsMalformed(t *testing.T) {
+	ctx := context.Background()
+	const dir = "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK"
+	for _, body := range []string{
+		`{"Objects":[]}`,
+		`{"Objects":[{"Hash":"` + dir + `","Links":[{"Name":"a","Hash":"notakey"}]}]}`,
+	} {
+		c := stubClient(t, stubCommand(t, "ls", []string{dir}, body))
+		if _, err := c.Ls(ctx, dir); err == nil {
+			t.Errorf("%s: expected an error", body)
+		}
+	}
+}
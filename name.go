@@ -0,0 +1,67 @@
+package interplanetary
+
+import (
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+)
+
+// IpnsEntry is a value published at an IPNS name.
+type IpnsEntry struct {
+	Name  string
+	Value string
+}
+
+// Publish publishes k at the IPNS name. If name is empty, the node's own
+// peer ID is used. The daemon has no keys but its own, so publishing at any
+// other name fails.
+func (c *client) Publish(ctx context.Context, name string, k Key) (*IpnsEntry, error) {
+	args := []string{k.String()}
+	if name != "" {
+		// the daemon refuses an explicit name, even its own
+		self, err := c.ID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if name != self.ID.Pretty() {
+			args = []string{name, k.String()}
+		}
+	}
+	req, err := c.request([]string{"name", "publish"}, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.IpnsEntry)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	return &IpnsEntry{Name: out.Name, Value: out.Value}, nil
+}
+
+// Resolve gets the value currently published at the IPNS name. If name is
+// empty, the node's own peer ID is used.
+func (c *client) Resolve(ctx context.Context, name string) (*IpnsEntry, error) {
+	if name == "" {
+		self, err := c.ID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		name = self.ID.Pretty()
+	}
+	req, err := c.request([]string{"name", "resolve"}, nil, name)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := res.Output().(string)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	return &IpnsEntry{Name: name, Value: value}, nil
+}
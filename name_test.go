@@ -0,0 +1,50 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+// mustParseKey parses s, failing the test if it is not a key.
+func mustParseKey(t *testing.T, s string) ipfs.Key {
+	k, err := ipfs.ParseKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestNames(t *testing.T) {
+	ctx := context.Background()
+	const value = "QmWfVY9y3xjsixTgbd9AorQxH7VtMpzfx2HaWtsoUYecaX"
+	pid, _, idOut := stubID(t)
+	id := pid.Pretty()
+	mux := http.NewServeMux()
+	mux.Handle("/api/v0/name/publish", stubCommand(t, "name/publish", []string{value}, `{"Name":"`+id+`","Value":"`+value+`"}`))
+	mux.Handle("/api/v0/id", stubCommand(t, "id", nil, idOut))
+	mux.Handle("/api/v0/name/resolve", stubCommand(t, "name/resolve", []string{id}, `"`+value+`"`))
+	c := stubClient(t, mux.ServeHTTP)
+
+	// publishing at the node's own name leaves the name out, as the daemon
+	// only accepts an implicit one
+	for _, name := range []string{"", id} {
+		entry, err := c.Publish(ctx, name, mustParseKey(t, value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Name != id || entry.Value != value {
+			t.Errorf("publish %q: got %+v", name, entry)
+		}
+	}
+	// resolving the empty name asks the node for its own ID first
+	entry, err := c.Resolve(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name != id || entry.Value != value {
+		t.Errorf("resolve: got %+v", entry)
+	}
+}
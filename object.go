@@ -0,0 +1,113 @@
+package interplanetary
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+)
+
+// Node is a raw merkledag node: opaque data and a set of links.
+type Node struct {
+	Links []Link
+	Data  []byte
+}
+
+// ObjectEncoding is the serialization of a node sent to ObjectPutRaw.
+type ObjectEncoding string
+
+const (
+	JSONEncoding     ObjectEncoding = "json"
+	ProtobufEncoding ObjectEncoding = "protobuf"
+)
+
+// ObjectGet gets the DAG node named by k.
+func (c *client) ObjectGet(ctx context.Context, k Key) (*Node, error) {
+	res, err := c.object(ctx, "get", k)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.Node)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	links, err := convertLinks(res.Request(), out.Links)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Links: links, Data: out.Data}, nil
+}
+
+// ObjectData returns the raw data of the DAG node named by k.
+func (c *client) ObjectData(ctx context.Context, k Key) (io.ReadCloser, error) {
+	res, err := c.object(ctx, "data", k)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser(res)
+}
+
+// ObjectLinks returns the links of the DAG node named by k.
+func (c *client) ObjectLinks(ctx context.Context, k Key) ([]Link, error) {
+	res, err := c.object(ctx, "links", k)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.Object)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	return convertLinks(res.Request(), out.Links)
+}
+
+func (c *client) object(ctx context.Context, sub string, k Key) (cmds.Response, error) {
+	req, err := c.request([]string{"object", sub}, nil, k.String())
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, req)
+}
+
+// ObjectPut stores n as a DAG node and returns its key.
+func (c *client) ObjectPut(ctx context.Context, n *Node) (Key, error) {
+	node := core_cmds.Node{
+		Links: make([]core_cmds.Link, len(n.Links)),
+		Data:  n.Data,
+	}
+	for i, l := range n.Links {
+		node.Links[i] = core_cmds.Link{Name: l.Name, Hash: l.Hash.String(), Size: l.Size}
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	return c.ObjectPutRaw(ctx, bytes.NewReader(data), JSONEncoding)
+}
+
+// ObjectPutRaw stores the DAG node read from r, serialized with enc, and
+// returns its key.
+func (c *client) ObjectPutRaw(ctx context.Context, r io.Reader, enc ObjectEncoding) (Key, error) {
+	req, err := c.request([]string{"object", "put"}, nil, string(enc))
+	if err != nil {
+		return nil, err
+	}
+	req.SetFiles(&cmds.SliceFile{Filename: "", Files: []cmds.File{
+		&cmds.ReaderFile{Filename: "data", Reader: r},
+	}})
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.Object)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	k, err := ParseKey(out.Hash)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	return k, nil
+}
@@ -0,0 +1,134 @@
+package interplanetary
+
+import (
+	stdcontext "context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+
+	cmds_http "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands/http"
+	config "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/config"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+)
+
+// Option configures a client created by NewClient.
+type Option func(*options)
+
+type options struct {
+	httpClient *http.Client
+	transport  http.RoundTripper
+	tlsConfig  *tls.Config
+	header     http.Header
+	userAgent  string
+	apiPath    string
+}
+
+// WithHTTPClient makes the client send its requests with hc instead of
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) { o.httpClient = hc }
+}
+
+// WithTransport makes the client send its requests through rt.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) { o.transport = rt }
+}
+
+// WithTLSConfig makes the client talk to the daemon over HTTPS, using cfg.
+// The transport in use must be an *http.Transport.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = cfg }
+}
+
+// WithHeader adds a header sent with every request, such as the credentials
+// expected by a proxy in front of the daemon.
+func WithHeader(key, value string) Option {
+	return func(o *options) { o.header.Add(key, value) }
+}
+
+// WithUserAgent overrides the User-Agent sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(o *options) { o.userAgent = ua }
+}
+
+// WithAPIPath sets the path under which the daemon serves its API. It
+// defaults to "/api/v0"; a daemon behind a proxy may be served under a
+// prefix, such as "/ipfs/api/v0".
+func WithAPIPath(p string) Option {
+	return func(o *options) {
+		o.apiPath = strings.TrimRight("/"+strings.Trim(p, "/"), "/")
+	}
+}
+
+func defaultOptions() *options {
+	return &options{
+		header:    make(http.Header),
+		userAgent: "/go-ipfs/" + config.CurrentVersionNumber + "/",
+		apiPath:   cmds_http.ApiPath,
+	}
+}
+
+// client returns the http.Client to send requests to the daemon at a with,
+// and the URL scheme to use.
+func (o *options) client(a *daemonAddr) (*http.Client, string, error) {
+	hc := o.httpClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	rt := o.transport
+	if rt == nil {
+		rt = hc.Transport
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	scheme := a.scheme
+	if o.tlsConfig != nil {
+		scheme = "https"
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		// a custom RoundTripper dials by itself
+		switch {
+		case o.tlsConfig != nil:
+			return nil, "", errors.New("TLS config requires an *http.Transport")
+		case a.network == "unix":
+			return nil, "", errors.New("unix socket requires an *http.Transport")
+		}
+		c := *hc
+		c.Transport = rt
+		return &c, scheme, nil
+	}
+
+	t = t.Clone()
+	if o.tlsConfig != nil {
+		t.TLSClientConfig = o.tlsConfig
+	}
+	// connections to the daemon are dialed over the network it was given
+	// on; others, such as to a proxy, are left alone
+	target := a.host
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		port := "80"
+		if scheme == "https" {
+			port = "443"
+		}
+		target = net.JoinHostPort(target, port)
+	}
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	t.DialContext = func(ctx stdcontext.Context, network, addr string) (net.Conn, error) {
+		if addr == target {
+			network, addr = a.network, a.address
+		}
+		return dial(ctx, network, addr)
+	}
+
+	// copy, so that a shared client such as http.DefaultClient is unchanged
+	c := *hc
+	c.Transport = t
+	return &c, scheme, nil
+}
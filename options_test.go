@@ -0,0 +1,63 @@
+package interplanetary_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestClientOptions(t *testing.T) {
+	var got *http.Request
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer s.Close()
+
+	tlsConfig := s.Client().Transport.(*http.Transport).TLSClientConfig
+	c := newClient(t, serverAddr(t, s),
+		ipfs.WithTLSConfig(tlsConfig),
+		ipfs.WithAPIPath("/ipfs/api/v0/"),
+		ipfs.WithHeader("Authorization", "Bearer secret"),
+		ipfs.WithUserAgent("test-agent"),
+	)
+	if err := c.SetConfig(context.Background(), "Tour.Last", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.URL.Path != "/ipfs/api/v0/config" {
+		t.Errorf("path: got %q", got.URL.Path)
+	}
+	if a := got.Header.Get("Authorization"); a != "Bearer secret" {
+		t.Errorf("Authorization: got %q", a)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != "test-agent" {
+		t.Errorf("User-Agent: got %q", ua)
+	}
+}
+
+func TestClientTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	var n int
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		n++
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	c := newClient(t, serverAddr(t, s), ipfs.WithTransport(rt))
+	if err := c.SetConfig(context.Background(), "Tour.Last", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 request through the transport, got %d", n)
+	}
+
+	_, err := ipfs.NewClient(serverAddr(t, s), ipfs.WithTransport(rt), ipfs.WithTLSConfig(&tls.Config{}))
+	if err == nil {
+		t.Error("expected an error using a TLS config with a custom transport")
+	}
+}
@@ -0,0 +1,77 @@
+package interplanetary
+
+import (
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	core_cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/core/commands"
+	mh "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multihash"
+)
+
+// PinType selects which pinned keys Pins lists.
+type PinType string
+
+const (
+	DirectPins    PinType = "direct"
+	IndirectPins  PinType = "indirect"
+	RecursivePins PinType = "recursive"
+	AllPins       PinType = "all"
+)
+
+// Pin pins the object named by k to local storage, protecting it from
+// garbage collection. If recursive is true, the objects it links to are
+// pinned as well.
+func (c *client) Pin(ctx context.Context, k Key, recursive bool) error {
+	return c.pinCmd(ctx, "add", k, recursive)
+}
+
+// Unpin removes the pin from the object named by k.
+func (c *client) Unpin(ctx context.Context, k Key, recursive bool) error {
+	return c.pinCmd(ctx, "rm", k, recursive)
+}
+
+func (c *client) pinCmd(ctx context.Context, sub string, k Key, recursive bool) error {
+	opts := map[string]interface{}{
+		"recursive": recursive,
+	}
+	req, err := c.request([]string{"pin", sub}, opts, k.String())
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, req)
+	return err
+}
+
+// Pins lists the keys pinned to local storage with the given type.
+func (c *client) Pins(ctx context.Context, t PinType) ([]Key, error) {
+	opts := map[string]interface{}{
+		"type": string(t),
+	}
+	req, err := c.request([]string{"pin", "ls"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.Output().(*core_cmds.KeyList)
+	if !ok {
+		return nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	keys, err := keysFromList(out)
+	if err != nil {
+		return nil, wrapMalformed(req, err)
+	}
+	return keys, nil
+}
+
+func keysFromList(l *core_cmds.KeyList) ([]Key, error) {
+	keys := make([]Key, len(l.Keys))
+	for i, k := range l.Keys {
+		key, err := KeyFromMultihash(mh.Multihash(k))
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
@@ -0,0 +1,56 @@
+package interplanetary
+
+import (
+	"io"
+
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+)
+
+// AddProgress describes how much of an add has been sent.
+type AddProgress struct {
+	Bytes int64  // bytes of file content sent so far
+	Files int    // files sent completely
+	Name  string // name of the file being sent
+}
+
+// WithProgress makes an add call fn each time file content is sent. fn is
+// called from the goroutine sending the request, one call at a time, and
+// should return quickly.
+func WithProgress(fn func(AddProgress)) AddOption {
+	return func(o *addOptions) { o.progress = fn }
+}
+
+// progressFile wraps a cmds.File tree, reporting the content read from its
+// files as it is sent.
+type progressFile struct {
+	cmds.File
+	state *AddProgress
+	fn    func(AddProgress)
+	done  bool
+}
+
+func newProgressFile(f cmds.File, fn func(AddProgress)) *progressFile {
+	return &progressFile{File: f, state: &AddProgress{}, fn: fn}
+}
+
+func (f *progressFile) NextFile() (cmds.File, error) {
+	child, err := f.File.NextFile()
+	if err != nil {
+		return nil, err
+	}
+	return &progressFile{File: child, state: f.state, fn: f.fn}, nil
+}
+
+func (f *progressFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.state.Bytes += int64(n)
+	f.state.Name = f.FileName()
+	if err == io.EOF && !f.done {
+		f.done = true
+		f.state.Files++
+	}
+	if n > 0 || err == io.EOF {
+		f.fn(*f.state)
+	}
+	return n, err
+}
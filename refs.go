@@ -0,0 +1,125 @@
+package interplanetary
+
+import (
+	"encoding/json"
+	"io"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+)
+
+// RefsOption changes which links Refs lists.
+type RefsOption int
+
+const (
+	// Recursive lists the links of linked objects as well.
+	Recursive RefsOption = iota
+	// Unique omits duplicate links.
+	Unique
+)
+
+// Refs lists the keys linked to by the object named by k. Keys are sent on
+// the returned channel as they are decoded from the daemon's response, and
+// the channel is closed at the end of the listing. At most one error is then
+// sent on the error channel. Cancelling ctx aborts the listing.
+func (c *client) Refs(ctx context.Context, k Key, opts ...RefsOption) (<-chan Key, <-chan error) {
+	keys := make(chan Key)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(keys)
+		if err := c.refs(ctx, k, opts, keys); err != nil {
+			errs <- err
+		}
+	}()
+	return keys, errs
+}
+
+func (c *client) refs(ctx context.Context, k Key, opts []RefsOption, keys chan<- Key) error {
+	options := make(map[string]interface{})
+	for _, o := range opts {
+		switch o {
+		case Recursive:
+			options["recursive"] = true
+		case Unique:
+			options["unique"] = true
+		}
+	}
+	req, err := c.request([]string{"refs"}, options, k.String())
+	if err != nil {
+		return err
+	}
+	body, err := c.stream(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// the output is a KeyList: {"Keys": ["<key>", ...]}
+	dec := json.NewDecoder(body)
+	if err := expectDelim(req, dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return decodeErr(req, err)
+		}
+		if t != "Keys" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return decodeErr(req, err)
+			}
+			continue
+		}
+		if err := expectDelim(req, dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var s string
+			if err := dec.Decode(&s); err != nil {
+				return decodeErr(req, err)
+			}
+			k, err := ParseKey(s)
+			if err != nil {
+				return wrapMalformed(req, err)
+			}
+			select {
+			case keys <- k:
+			case <-ctx.Done():
+				return errDone(req, ctx)
+			}
+		}
+		if err := expectDelim(req, dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(req, dec, '}')
+}
+
+func expectDelim(req cmds.Request, dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err == io.EOF {
+		return errMalformed(req, "malformed response")
+	}
+	if err != nil {
+		return decodeErr(req, err)
+	}
+	if t != d {
+		return errMalformed(req, "malformed response")
+	}
+	return nil
+}
+
+// decodeErr wraps an error decoding the output of req if it comes from the
+// output itself, rather than from reading it.
+func decodeErr(req cmds.Request, err error) error {
+	switch err.(type) {
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return wrapMalformed(req, err)
+	}
+	if err == io.ErrUnexpectedEOF {
+		return wrapMalformed(req, err)
+	}
+	return err
+}
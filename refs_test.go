@@ -0,0 +1,53 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestRefs(t *testing.T) {
+	const (
+		root = "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK"
+		a    = "QmWfVY9y3xjsixTgbd9AorQxH7VtMpzfx2HaWtsoUYecaX"
+		b    = "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ"
+	)
+	refs := stubCommand(t, "refs", []string{root}, `{"Keys":["`+a+`","`+b+`"]}`)
+	c := stubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("recursive") != "true" || q.Get("unique") != "true" {
+			t.Errorf("unexpected options %v", q)
+		}
+		refs(w, r)
+	})
+
+	keys, errs := c.Refs(context.Background(), mustParseKey(t, root), ipfs.Recursive, ipfs.Unique)
+	var got []string
+	for k := range keys {
+		got = append(got, k.String())
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != a || got[1] != b {
+		t.Errorf("refs: got %v", got)
+	}
+}
+
+func TestRefsMalformed(t *testing.T) {
+	const root = "QmWQGUywxLpAFrwdRWuwfMU1tVpk6xfT9oBRusYkTKHRQK"
+	for _, body := range []string{
+		`{"Keys":["notakey"]}`,
+		`{"Keys":[`,
+		`[]`,
+	} {
+		c := stubClient(t, stubCommand(t, "refs", []string{root}, body))
+		keys, errs := c.Refs(context.Background(), mustParseKey(t, root))
+		for range keys {
+		}
+		if err := <-errs; err == nil {
+			t.Errorf("%s: expected an error", body)
+		}
+	}
+}
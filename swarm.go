@@ -0,0 +1,103 @@
+package interplanetary
+
+import (
+	gopath "path"
+	"strings"
+
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+	cmds "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/commands"
+	peer "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/peer"
+	errors "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-ipfs/util/debugerror"
+	ma "github.com/maybebtc/interplanetary/Godeps/_workspace/src/github.com/jbenet/go-multiaddr"
+)
+
+// PeerAddr is a peer and an address it can be reached at. Its string form
+// is "<multiaddr>/<peerID>". Addr may be nil if the address is unknown.
+type PeerAddr struct {
+	Addr ma.Multiaddr
+	ID   peer.ID
+}
+
+// ParsePeerAddr parses a peer address of the form "<multiaddr>/<peerID>".
+func ParsePeerAddr(s string) (PeerAddr, error) {
+	addr, err := ma.NewMultiaddr(gopath.Dir(s))
+	if err != nil {
+		return PeerAddr{}, err
+	}
+	id := peer.DecodePrettyID(gopath.Base(s))
+	if len(id) == 0 {
+		return PeerAddr{}, errors.Errorf("invalid peer ID in %q", s)
+	}
+	return PeerAddr{Addr: addr, ID: id}, nil
+}
+
+func (p PeerAddr) String() string {
+	if p.Addr == nil {
+		return p.ID.Pretty()
+	}
+	return p.Addr.String() + "/" + p.ID.Pretty()
+}
+
+// stringList mirrors the unexported output type of the swarm commands.
+type stringList struct {
+	Strings []string
+}
+
+// SwarmPeers lists the peers the node has open connections to.
+func (c *client) SwarmPeers(ctx context.Context) ([]PeerAddr, error) {
+	req, out, err := c.swarm(ctx, "peers")
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]PeerAddr, len(out))
+	for i, s := range out {
+		peers[i], err = ParsePeerAddr(s)
+		if err != nil {
+			return nil, wrapMalformed(req, err)
+		}
+	}
+	return peers, nil
+}
+
+// SwarmConnect opens connections to the given peers. It returns an error
+// describing every connection that failed.
+func (c *client) SwarmConnect(ctx context.Context, peers ...PeerAddr) error {
+	args := make([]string, len(peers))
+	for i, p := range peers {
+		args[i] = p.String()
+	}
+	_, out, err := c.swarm(ctx, "connect", args...)
+	if err != nil {
+		return err
+	}
+	// each line reads "connect <peerID> success" or
+	// "connect <peerID> failure: <reason>"
+	var failures []string
+	for _, s := range out {
+		if !strings.HasSuffix(s, " success") {
+			failures = append(failures, s)
+		}
+	}
+	if len(failures) > 0 {
+		return &Error{Kind: ErrDaemon, Path: []string{"swarm", "connect"}, Message: strings.Join(failures, "; ")}
+	}
+	return nil
+}
+
+// swarm runs the swarm subcommand sub. It returns the request, for errors
+// about the output, and the output lines.
+func (c *client) swarm(ctx context.Context, sub string, args ...string) (cmds.Request, []string, error) {
+	req, err := c.requestAs(&stringList{}, []string{"swarm", sub}, nil, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, ok := res.Output().(*stringList)
+	if !ok {
+		return nil, nil, errMalformed(res.Request(), "unrecognized output format")
+	}
+	return req, out.Strings, nil
+}
@@ -0,0 +1,56 @@
+package interplanetary_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	ipfs "github.com/maybebtc/interplanetary"
+	context "github.com/maybebtc/interplanetary/Godeps/_workspace/src/code.google.com/p/go.net/context"
+)
+
+func TestSwarm(t *testing.T) {
+	ctx := context.Background()
+	const (
+		id   = "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ"
+		addr = "/ip4/104.131.131.82/tcp/4001/" + id
+	)
+	p, err := ipfs.ParsePeerAddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID.Pretty() != id || p.Addr.String() != "/ip4/104.131.131.82/tcp/4001" || p.String() != addr {
+		t.Errorf("parse: got %v", p)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v0/swarm/peers", stubCommand(t, "swarm/peers", nil, `{"Strings":["`+addr+`"]}`))
+	mux.Handle("/api/v0/swarm/connect", stubCommand(t, "swarm/connect", []string{addr}, `{"Strings":["connect `+id+` success"]}`))
+	c := stubClient(t, mux.ServeHTTP)
+
+	peers, err := c.SwarmPeers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].String() != addr {
+		t.Errorf("peers: got %v", peers)
+	}
+	if err := c.SwarmConnect(ctx, p); err != nil {
+		t.Error(err)
+	}
+
+	c = stubClient(t, stubCommand(t, "swarm/connect", []string{addr}, `{"Strings":["connect `+id+` failure: dial attempt failed"]}`))
+	if err := c.SwarmConnect(ctx, p); err == nil {
+		t.Error("connect: expected an error")
+	}
+}
+
+func TestSwarmMalformed(t *testing.T) {
+	ctx := context.Background()
+	c := stubClient(t, stubCommand(t, "swarm/peers", nil, `{"Strings":["/ip4/104.131.131.82/tcp/4001/0OIl"]}`))
+	_, err := c.SwarmPeers(ctx)
+	e, ok := err.(*ipfs.Error)
+	if !ok || e.Kind != ipfs.ErrMalformedResponse || strings.Join(e.Path, " ") != "swarm peers" {
+		t.Errorf("expected a malformed swarm peers response, got %#v", err)
+	}
+}